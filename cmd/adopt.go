@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var adoptRuleFlags []string
+var adoptRelativeFlag bool
+var adoptForceFlag bool
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Turn rule copies in .cursor/rules/ back into symlinks to the canonical rules",
+	Run: func(cmd *cobra.Command, args []string) {
+		baseDir, destRulesPath, err := installPaths()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := service.LoadManifest(baseDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts := service.AdoptOptions{
+			Rules:         adoptRuleFlags,
+			Source:        resolveRuleSource(os.Stderr),
+			DestRulesPath: destRulesPath,
+			Relative:      adoptRelativeFlag,
+			Force:         adoptForceFlag,
+			Manifest:      manifest,
+			Stdout:        os.Stdout,
+			Stderr:        os.Stderr,
+		}
+		if err := service.AdoptRules(cmd.Context(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Adopt error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := manifest.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	adoptCmd.Flags().StringSliceVar(&adoptRuleFlags, "rule", nil, "Rule(s) to adopt (default: every copy with a canonical rule)")
+	adoptCmd.Flags().BoolVar(&adoptRelativeFlag, "relative", false, "Create relative symlinks instead of absolute ones")
+	adoptCmd.Flags().BoolVar(&adoptForceFlag, "force", false, "Adopt copies even when they differ from the canonical rule (local edits are lost)")
+	adoptCmd.Flags().BoolVar(&globalFlag, "global", false, "Adopt rules in the home directory (~/) instead of the current directory")
+	rootCmd.AddCommand(adoptCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var bootstrapDestFlag string
+var bootstrapUpdateFlag bool
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Export the embedded rules into your rules directory so you can customize them",
+	Long: `bootstrap writes every embedded rule into your rules directory ($XDG_CONFIG_HOME/ai-rules or
+~/ai-rules by default) so that customizing one rule does not hide the others. Existing rules are
+never overwritten. Run it again with --update after upgrading to bring in new rules and refresh
+rules you have not edited.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dest := bootstrapDestFlag
+		if dest == "" {
+			dest = userRulesDir()
+		}
+		opts := service.BootstrapOptions{
+			Embedded: embeddedRules,
+			Dest:     dest,
+			Version:  version,
+			Update:   bootstrapUpdateFlag,
+			Stdout:   os.Stdout,
+		}
+		if err := service.BootstrapRules(cmd.Context(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Bootstrap error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Rules directory %s is up to date with version %s\n", dest, version)
+	},
+}
+
+func init() {
+	bootstrapCmd.Flags().StringVar(&bootstrapDestFlag, "dest", "", "Rules directory to export into (default: $XDG_CONFIG_HOME/ai-rules or ~/ai-rules)")
+	bootstrapCmd.Flags().BoolVar(&bootstrapUpdateFlag, "update", false, "Add rules from a newer binary and refresh rules you have not edited")
+	rootCmd.AddCommand(bootstrapCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var checkTargetsFlag bool
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check installed rules for drift from their sources; exits non-zero when anything is stale",
+	Long: `check --targets re-renders every file recorded in .ai-rules-link.json from the current rule
+sources, for every target, and compares the result with what is on disk without writing anything.
+Drifted files are reported per target with a diff, and the command exits with status 1, so it can
+run in CI. Files whose rule source is not on this machine, such as rules from ~/ai-rules on a CI
+runner, are listed apart and are not drift.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !checkTargetsFlag {
+			fmt.Fprintln(os.Stderr, "Nothing to check. Use --targets to check installed targets for drift.")
+			os.Exit(1)
+		}
+		baseDir, _, err := installPaths()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := service.LoadManifest(baseDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		drift, checked, err := service.CheckTargets(cmd.Context(), service.CheckOptions{Manifest: manifest, Embedded: embeddedRules})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Check error: %v\n", err)
+			os.Exit(1)
+		}
+		if checked == 0 {
+			fmt.Printf("No installed rules recorded in %s.\n", service.ManifestFilename)
+			return
+		}
+		var unavailable []service.Drift
+		stale := drift[:0]
+		for _, d := range drift {
+			if d.Unavailable {
+				unavailable = append(unavailable, d)
+				continue
+			}
+			stale = append(stale, d)
+		}
+		drift = stale
+		if len(unavailable) > 0 {
+			fmt.Printf("Could not check %d file(s) because their rule source is not on this machine:\n", len(unavailable))
+			for _, d := range unavailable {
+				fmt.Printf("  %s: %s\n", d.Path, d.Problem)
+			}
+		}
+		if len(drift) == 0 {
+			fmt.Printf("All %d checked file(s) match their rules.\n", checked-len(unavailable))
+			return
+		}
+
+		byTarget := map[string][]service.Drift{}
+		for _, d := range drift {
+			byTarget[d.Target] = append(byTarget[d.Target], d)
+		}
+		targets := make([]string, 0, len(byTarget))
+		for t := range byTarget {
+			targets = append(targets, t)
+		}
+		sort.Strings(targets)
+		for _, t := range targets {
+			fmt.Printf("%s: %d file(s) out of date\n", t, len(byTarget[t]))
+			for _, d := range byTarget[t] {
+				if d.Problem != "" {
+					fmt.Printf("  %s: %s\n", d.Path, d.Problem)
+					continue
+				}
+				fmt.Printf("  %s:\n%s", d.Path, d.Diff)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d of %d checked file(s) have drifted. Run 'ai-rules-link rules' again or 'ai-rules-link watch' to regenerate them.\n", len(drift), checked-len(unavailable))
+		os.Exit(1)
+	},
+}
+
+func init() {
+	checkCmd.Flags().BoolVar(&checkTargetsFlag, "targets", false, "Re-render every installed target in memory and report files that differ from disk")
+	checkCmd.Flags().BoolVar(&globalFlag, "global", false, "Check rules installed in the home directory (~/) instead of the current directory")
+	rootCmd.AddCommand(checkCmd)
+}
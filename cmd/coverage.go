@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var coverageTopFlag int
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Report which files the installed rules cover, and the file types and directories no scoped rule covers",
+	Long: `coverage walks the project, skipping hidden directories and anything .gitignore excludes, and
+evaluates every installed rule for every file the way 'explain' does. Rules attached to every file are
+listed separately; the rest of the report is about scoped rules, those attached by glob or only read
+for a package's files, and the file types and directories none of them covers.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := service.LoadManifest(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		report, err := service.ProjectCoverage(cmd.Context(), service.CoverageOptions{Manifest: manifest, Embedded: embeddedRules})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Coverage error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Checked %d file(s).\n", report.Files)
+		if len(report.Always) > 0 {
+			fmt.Printf("Always applied: %s\n", strings.Join(report.Always, ", "))
+		}
+		if len(report.Rules) > 0 {
+			fmt.Println("Scoped rules:")
+			for _, c := range report.Rules {
+				fmt.Printf("  %-20s %d file(s)\n", c.Name, c.Files)
+			}
+		}
+		if report.Uncovered == 0 {
+			fmt.Println("Every file is covered by a scoped rule.")
+			return
+		}
+		fmt.Printf("%d file(s) have no scoped rule.\n", report.Uncovered)
+		fmt.Println("By file type:")
+		for _, c := range top(report.UncoveredTypes) {
+			fmt.Printf("  %d %s file(s) have no scoped rule\n", c.Files, c.Name)
+		}
+		fmt.Println("By directory:")
+		for _, c := range top(report.UncoveredDirs) {
+			fmt.Printf("  %-20s %d file(s)\n", c.Name+"/", c.Files)
+		}
+	},
+}
+
+// top returns the first --top counts.
+func top(counts []service.CoverageCount) []service.CoverageCount {
+	if coverageTopFlag > 0 && len(counts) > coverageTopFlag {
+		return counts[:coverageTopFlag]
+	}
+	return counts
+}
+
+func init() {
+	coverageCmd.Flags().IntVar(&coverageTopFlag, "top", 10, "How many file types and directories to list (0 lists all)")
+	rootCmd.AddCommand(coverageCmd)
+}
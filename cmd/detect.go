@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai-rules-link/internal/detect"
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var detectWriteFlag bool
+var detectForceFlag bool
+
+var detectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Show the technologies found in the current project and the rules they map to",
+	Long: `Show the technologies found in the current project and the rules they map to.
+
+When technologies are found in subdirectories, detect proposes a ` + service.PackagesFilename + ` that maps
+each package to its rules; --write saves it for 'rules' to install.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		findings, err := detect.Detect(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Detect error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(findings) == 0 {
+			fmt.Println("No known technologies detected. Pick rules with --rule.")
+			return
+		}
+		source := resolveRuleSource(io.Discard)
+		for _, f := range findings {
+			note := ""
+			if !source.HasRule(f.Rule) {
+				note = fmt.Sprintf("  (no %s in %s rules)", service.RuleFilename(f.Rule), source.Name)
+			}
+			fmt.Printf("  %-8s %s%s\n", f.Rule, f.Evidence, note)
+		}
+		byDir := detect.RulesByDir(findings)
+		if len(byDir) == 1 && byDir["."] != nil && !detectWriteFlag {
+			rules := autoRules(source, cwd, true, nil, io.Discard)
+			fmt.Printf("Install with: ai-rules-link rules --auto (same as --rule=%s)\n", strings.Join(rules, " --rule="))
+			return
+		}
+		config := proposePackages(byDir, source)
+		path := filepath.Join(cwd, service.PackagesFilename)
+		if !detectWriteFlag {
+			fmt.Printf("Proposed %s (save it with --write):\n\n%s", service.PackagesFilename, config.Marshal())
+			return
+		}
+		if _, err := os.Stat(path); err == nil && !detectForceFlag {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists; use --force to overwrite it\n", service.PackagesFilename)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, config.Marshal(), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %s. Install its rules with: ai-rules-link rules\n", service.PackagesFilename)
+	},
+}
+
+// proposePackages maps the project root to base and the rules detected there, and each subdirectory
+// with detections to its own rules. Rules missing from source are left out.
+func proposePackages(byDir map[string][]string, source service.RuleSource) service.PackagesConfig {
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		if dir != "." {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	config := service.PackagesConfig{Scope: service.ScopeNested}
+	for _, dir := range append([]string{"."}, dirs...) {
+		var rules []string
+		if dir == "." {
+			rules = append(rules, "base")
+		}
+		for _, rule := range byDir[dir] {
+			if source.HasRule(rule) {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) > 0 {
+			config.Packages = append(config.Packages, service.Package{Path: dir, Rules: rules})
+		}
+	}
+	return config
+}
+
+// autoRules returns the rules --auto installs: base unless dir is a package of a larger project, the
+// rules detected in dir that exist in source, and any rules given explicitly. Detected rules without
+// a rule file are reported to warn.
+func autoRules(source service.RuleSource, dir string, withBase bool, explicit []string, warn io.Writer) []string {
+	var rules []string
+	if withBase {
+		rules = append(rules, "base")
+	}
+	add := func(rule string) {
+		for _, r := range rules {
+			if r == rule {
+				return
+			}
+		}
+		rules = append(rules, rule)
+	}
+	findings, err := detect.Detect(dir)
+	if err != nil {
+		fmt.Fprintf(warn, "[ai-rules-link] Could not detect project technologies: %v\n", err)
+	}
+	for _, rule := range detect.Rules(findings) {
+		if !source.HasRule(rule) {
+			fmt.Fprintf(warn, "[ai-rules-link] Detected %s, but there is no %s in %s rules.\n", rule, service.RuleFilename(rule), source.Name)
+			continue
+		}
+		add(rule)
+	}
+	for _, rule := range explicit {
+		add(rule)
+	}
+	return rules
+}
+
+func init() {
+	detectCmd.Flags().BoolVar(&detectWriteFlag, "write", false, "Write the proposed package mapping to "+service.PackagesFilename)
+	detectCmd.Flags().BoolVar(&detectForceFlag, "force", false, "Overwrite an existing "+service.PackagesFilename)
+	rootCmd.AddCommand(detectCmd)
+}
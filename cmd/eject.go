@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var ejectRuleFlags []string
+
+var ejectCmd = &cobra.Command{
+	Use:   "eject",
+	Short: "Replace the rule symlinks recorded in .ai-rules-link.json with real copies so they can be committed",
+	Run: func(cmd *cobra.Command, args []string) {
+		baseDir, _, err := installPaths()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := service.LoadManifest(baseDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts := service.EjectOptions{
+			Rules:    ejectRuleFlags,
+			Manifest: manifest,
+			Stdout:   os.Stdout,
+			Stderr:   os.Stderr,
+		}
+		if err := service.EjectRules(cmd.Context(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Eject error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := manifest.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ejectCmd.Flags().StringSliceVar(&ejectRuleFlags, "rule", nil, "Rule(s) to eject (default: every recorded rule symlink)")
+	ejectCmd.Flags().BoolVar(&globalFlag, "global", false, "Eject rules in the home directory (~/) instead of the current directory")
+	rootCmd.AddCommand(ejectCmd)
+}
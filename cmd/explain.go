@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <file>",
+	Short: "Show which installed rules would be attached to a file, and why",
+	Long: `explain evaluates every installed rule against a file in the project, for every target: Cursor's
+rules directories as they are on disk, and the other targets from .ai-rules-link.json. It shows which
+rules attach because of alwaysApply or a matching glob, which the model may pick from their
+description, and which are skipped, shadowed by another copy, or ignored by the tool.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		file, err := filepath.Abs(args[0])
+		if err == nil {
+			file, err = filepath.Rel(cwd, file)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := service.LoadManifest(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		explanations, err := service.ExplainFile(service.ExplainOptions{Manifest: manifest, Embedded: embeddedRules, File: file})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(explanations) == 0 {
+			fmt.Println("No installed rules found. Install some with 'ai-rules-link rules'.")
+			return
+		}
+		fmt.Printf("Rules for %s:\n", filepath.ToSlash(file))
+		target := ""
+		for _, x := range explanations {
+			if x.Target != target {
+				target = x.Target
+				fmt.Printf("%s:\n", target)
+			}
+			fmt.Printf("  %-8s %-12s %s: %s\n", x.Verdict, x.Rule, x.Path, x.Reason)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"ai-rules-link/internal/domain"
 	"ai-rules-link/internal/service"
 
 	"github.com/spf13/cobra"
@@ -16,6 +20,12 @@ var consolidateFlag bool
 var globalFlag bool
 var embeddedRules fs.FS // will be set from main.go
 var forceFlag bool
+var modeFlag string
+var targetFlags []string
+var autoFlag bool
+var pathFlag string
+var scopeFlag string
+var autoGlobsFlag bool
 
 func SetEmbeddedRules(fs fs.FS) {
 	embeddedRules = fs
@@ -23,131 +33,219 @@ func SetEmbeddedRules(fs fs.FS) {
 
 var rulesCmd = &cobra.Command{
 	Use:   "rules",
-	Short: "Symlink selected rules into .cursor/rules/ for Cursor IDE integration, or consolidate all into one file if --consolidate is set",
+	Short: "Install selected rules for one or more AI tools (Cursor by default), as symlinks, copies, hard links or one consolidated file",
 	Run: func(cmd *cobra.Command, args []string) {
-		cwd, err := os.Getwd()
+		baseDir, _, err := installPaths()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		destRulesPath := os.Getenv("DEST_RULES_PATH")
-		if destRulesPath == "" {
-			destRulesPath = ".cursor/rules"
-		}
-		var baseDir string
-		if globalFlag {
-			baseDir = os.Getenv("HOME")
-		} else {
-			baseDir = cwd
+		targets, err := selectedTargets(targetFlags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		// 1. Try XDG_CONFIG_HOME/ai-rules
-		rulesDir := ""
-		xdg := os.Getenv("XDG_CONFIG_HOME")
-		if xdg != "" {
-			candidate := filepath.Join(xdg, "ai-rules")
-			if stat, err := os.Stat(candidate); err == nil && stat.IsDir() {
-				rulesDir = candidate
-			} else {
-
-				fmt.Fprintf(os.Stdout, "[ai-rules-link] Warning: No rules found in $XDG_CONFIG_HOME/ai-rules (%s)\n", candidate)
-			}
+		mode, err := installMode()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		// 2. Try ~/ai-rules
-		if rulesDir == "" {
-			home, _ := os.UserHomeDir()
-			candidate := filepath.Join(home, "ai-rules")
-			if stat, err := os.Stat(candidate); err == nil && stat.IsDir() {
-				rulesDir = candidate
-			} else {
-				fmt.Fprintf(os.Stdout, "[ai-rules-link] Warning: No rules found in ~/ai-rules (%s)\n", candidate)
-			}
+
+		manifest, err := service.LoadManifest(baseDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		usingEmbedded := false
-		if rulesDir == "" {
-			usingEmbedded = true
-			fmt.Fprintf(os.Stdout, "[ai-rules-link] Using embedded rules.\n")
+		source := resolveRuleSource(os.Stderr)
+		packages, scoped, err := rulePackages(source, baseDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-
-		if consolidateFlag {
-			var merged []byte
-			for _, flag := range ruleFlags {
-				filename := flag + "rules.mdc"
-				var content []byte
-				var err error
-				if usingEmbedded {
-					content, err = fs.ReadFile(embeddedRules, filepath.Join("rules", filename))
-				} else {
-					content, err = os.ReadFile(filepath.Join(rulesDir, filename))
+		var notFound error
+		for _, p := range packages {
+			if len(p.Rules) == 0 && len(packages) > 1 {
+				continue
+			}
+			if p.Path != "." {
+				fmt.Printf("[ai-rules-link] Package %s: %s\n", p.Path, strings.Join(p.Rules, ", "))
+			}
+			for _, target := range targets {
+				opts := service.InstallOptions{
+					Rules:     p.Rules,
+					Mode:      mode,
+					Source:    source,
+					Target:    target,
+					BaseDir:   baseDir,
+					Global:    globalFlag,
+					Dir:       service.PackageDir(p.Path),
+					Scoped:    scoped,
+					AutoGlobs: autoGlobsFlag,
+					Force:     forceFlag,
+					Manifest:  manifest,
+					Stdout:    os.Stdout,
+					Stderr:    os.Stderr,
+				}
+				err := service.InstallRules(cmd.Context(), opts)
+				if errors.Is(err, service.ErrRuleNotFound) {
+					notFound = err
+					continue
 				}
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Could not read %s: %v\n", filename, err)
+					fmt.Fprintf(os.Stderr, "Install error (%s): %v\n", target.Name(), err)
 					os.Exit(1)
 				}
-				merged = append(merged, content...)
-				merged = append(merged, '\n')
 			}
-			outDir := filepath.Join(baseDir, destRulesPath)
-			os.MkdirAll(outDir, 0755)
-			outFile := filepath.Join(outDir, "consolidatedrules.mdc")
-			if err := os.WriteFile(outFile, merged, 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to write consolidated file: %v\n", err)
-				os.Exit(1)
+		}
+		if err := manifest.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if notFound != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v. Run 'ai-rules-link which <rule>' to see where rules are looked up.\n", notFound)
+			os.Exit(1)
+		}
+	},
+}
+
+// rulePackages returns what to install: the rules selected with --rule and --auto for the project or
+// the package given with --path, or the packages in .ai-rules.yaml when no rules are selected. It
+// also reports whether packages are scoped by glob from the project root instead of nested.
+func rulePackages(source service.RuleSource, baseDir string) ([]service.Package, bool, error) {
+	var config service.PackagesConfig
+	found := false
+	if !globalFlag {
+		var err error
+		if config, found, err = service.LoadPackages(baseDir); err != nil {
+			return nil, false, err
+		}
+	}
+	scope := scopeFlag
+	if scope == "" {
+		scope = config.Scope
+	}
+	var scoped bool
+	switch scope {
+	case "", service.ScopeNested:
+	case service.ScopeGlobs:
+		scoped = true
+	default:
+		return nil, false, fmt.Errorf("unknown --scope %q (use %s or %s)", scope, service.ScopeNested, service.ScopeGlobs)
+	}
+
+	path := "."
+	if pathFlag != "" {
+		if globalFlag {
+			return nil, false, fmt.Errorf("--path cannot be combined with --global")
+		}
+		var err error
+		if path, err = service.CleanPackagePath(pathFlag); err != nil {
+			return nil, false, err
+		}
+	}
+	if len(ruleFlags) == 0 && !autoFlag && found {
+		if pathFlag == "" {
+			return config.Packages, scoped, nil
+		}
+		for _, p := range config.Packages {
+			if p.Path == path {
+				return []service.Package{p}, scoped, nil
 			}
-			fmt.Printf("Consolidated rules written to: %s\n", outFile)
-			return
 		}
+		return nil, false, fmt.Errorf("%s has no package %s", service.PackagesFilename, path)
+	}
+	rules := ruleFlags
+	if autoFlag {
+		rules = autoRules(source, filepath.Join(baseDir, path), path == ".", ruleFlags, os.Stdout)
+		fmt.Printf("[ai-rules-link] Installing detected rules: %s\n", strings.Join(rules, ", "))
+	}
+	return []service.Package{{Path: path, Rules: rules}}, scoped, nil
+}
 
-		if usingEmbedded {
-			// Copy from embedded rules into destRulesPath
-			outDir := filepath.Join(baseDir, destRulesPath)
-			os.MkdirAll(outDir, 0755)
-			for _, flag := range ruleFlags {
-				filename := flag + "rules.mdc"
-				srcPath := filepath.Join("rules", filename)
-				content, err := fs.ReadFile(embeddedRules, srcPath)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Embedded rules file does not exist for '%s': %s\n", flag, srcPath)
-					continue
-				}
-				dst := filepath.Join(outDir, filename)
-				if !forceFlag {
-					dstContent, err := os.ReadFile(dst)
-					if err == nil {
-						// File exists, check if content matches
-						if string(dstContent) != string(content) {
-							fmt.Fprintf(os.Stdout, "[ai-rules-link] Skipping %s: destination file has been modified by the user. Use --force to overwrite.\n", dst)
-							continue
-						}
-					}
-				}
-				if err := os.WriteFile(dst, content, 0644); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to copy embedded rule for %s: %v\n", filename, err)
-				} else {
-					fmt.Fprintf(os.Stdout, "Copied embedded %s into %s\n", filename, outDir)
-				}
+// selectedTargets looks up the targets named on the command line. The Cursor target honors
+// DEST_RULES_PATH for its rules directory.
+func selectedTargets(names []string) ([]domain.Target, error) {
+	if len(names) == 0 {
+		names = []string{service.DefaultTarget}
+	}
+	var targets []domain.Target
+	for _, name := range names {
+		if name == "cursor" {
+			if dir := os.Getenv("DEST_RULES_PATH"); dir != "" {
+				targets = append(targets, service.CursorTarget{Dir: dir})
+				continue
 			}
-			return
 		}
+		target, err := service.LookupTarget(name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
 
-		opts := service.SymlinkOptions{
-			Rules:         ruleFlags,
-			CanonicalDir:  rulesDir,
-			DestRulesPath: filepath.Join(baseDir, destRulesPath),
-			Stdout:        os.Stdout,
-			Stderr:        os.Stderr,
-		}
-		if err := service.SymlinkRules(cmd.Context(), opts); err != nil {
-			fmt.Fprintf(os.Stderr, "Symlink error: %v\n", err)
-			os.Exit(1)
+// resolveRuleSource picks the rule source for the current project. With --global the
+// project's .ai-rules/ directory is not consulted.
+func resolveRuleSource(warn io.Writer) service.RuleSource {
+	projectDir := ""
+	if !globalFlag {
+		projectDir, _ = os.Getwd()
+	}
+	return service.ResolveRuleSource(projectDir, embeddedRules, warn)
+}
+
+// installPaths returns the directory installs are relative to (the project, or home with --global)
+// and the absolute rules directory inside it, honoring DEST_RULES_PATH.
+func installPaths() (baseDir, destRulesPath string, err error) {
+	if globalFlag {
+		baseDir, err = os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("could not determine home directory: %w", err)
 		}
-	},
+	} else {
+		baseDir, err = os.Getwd()
+		if err != nil {
+			return "", "", err
+		}
+	}
+	rel := os.Getenv("DEST_RULES_PATH")
+	if rel == "" {
+		rel = ".cursor/rules"
+	}
+	return baseDir, filepath.Join(baseDir, rel), nil
+}
+
+// installMode combines --mode with the legacy --consolidate flag.
+func installMode() (domain.InstallMode, error) {
+	var mode domain.InstallMode
+	if modeFlag != "" {
+		m, err := domain.ParseInstallMode(modeFlag)
+		if err != nil {
+			return "", err
+		}
+		mode = m
+	}
+	if consolidateFlag {
+		if mode != "" && mode != domain.ModeConsolidate {
+			return "", fmt.Errorf("--consolidate conflicts with --mode=%s", mode)
+		}
+		mode = domain.ModeConsolidate
+	}
+	return mode, nil
 }
 
 func init() {
-	rulesCmd.Flags().StringSliceVar(&ruleFlags, "rule", nil, "Rule(s) to symlink (e.g., --rule=go --rule=docker --rule=base)")
-	rulesCmd.Flags().BoolVar(&consolidateFlag, "consolidate", false, "Merge all selected rules into one file instead of symlinking")
-	rulesCmd.Flags().BoolVar(&globalFlag, "global", false, "Create rules in the home directory (~/) instead of the current directory")
+	rulesCmd.Flags().StringSliceVar(&ruleFlags, "rule", nil, "Rule(s) to install (e.g., --rule=go --rule=docker --rule=base); name#section installs only that section")
+	rulesCmd.Flags().BoolVar(&autoFlag, "auto", false, "Install base plus the rules for the technologies detected in the project (see 'detect'); combines with --rule")
+	rulesCmd.Flags().StringVar(&pathFlag, "path", "", "Install for a package of a monorepo, e.g. --path=services/api (default: the packages in "+service.PackagesFilename+", or the project)")
+	rulesCmd.Flags().StringVar(&scopeFlag, "scope", "", "How packages get their rules: nested (rules directories inside each package) or globs (root rules limited to the package by globs)")
+	rulesCmd.Flags().BoolVar(&autoGlobsFlag, "auto-globs", false, "Apply always-on language rules only to files of their language (e.g. gorules to **/*.go); such rules are copied, not linked")
+	rulesCmd.Flags().StringVar(&modeFlag, "mode", "", "Install mode: symlink, relsymlink, copy, hardlink or consolidate (default: symlink for a rules directory, copy for embedded rules)")
+	rulesCmd.Flags().StringSliceVar(&targetFlags, "target", nil, "AI tool(s) to install rules for (e.g., --target=cursor,claude; default: cursor)")
+	rulesCmd.Flags().BoolVar(&consolidateFlag, "consolidate", false, "Merge all selected rules into one file (same as --mode=consolidate)")
+	rulesCmd.Flags().BoolVar(&globalFlag, "global", false, "Install personal rules where each target reads user-level rules in your home directory (e.g. ~/.claude/CLAUDE.md)")
 	rulesCmd.Flags().BoolVar(&forceFlag, "force", false, "Overwrite destination files even if they have been modified by the user")
 	rootCmd.AddCommand(rulesCmd)
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var importFromFlags []string
+var importPrefixFlag string
+var importProjectFlag bool
+var importDirFlag string
+var importForceFlag bool
+var importDryRunFlag bool
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Split existing instruction files (.cursorrules, CLAUDE.md, AGENTS.md, ...) into <name>rules.mdc rules",
+	Long: `import reads hand-written instruction files from the current project (.cursorrules, CLAUDE.md,
+.github/copilot-instructions.md, .windsurfrules and AGENTS.md by default), splits each at its
+top-level headings and writes one always-applied <name>rules.mdc rule per section into your rules
+directory. Rule names start with --prefix, which defaults to the project directory name.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dir := importDirFlag
+		switch {
+		case dir != "":
+		case importProjectFlag:
+			dir = filepath.Join(cwd, service.ProjectRulesDir)
+		default:
+			dir = userRulesDir()
+		}
+		prefix := importPrefixFlag
+		if !cmd.Flags().Changed("prefix") {
+			prefix = filepath.Base(cwd)
+		}
+		opts := service.ImportOptions{
+			ProjectDir: cwd,
+			Files:      importFromFlags,
+			Prefix:     prefix,
+			Dir:        dir,
+			Force:      importForceFlag,
+			DryRun:     importDryRunFlag,
+			Stdout:     os.Stdout,
+		}
+		imported, err := service.ImportRules(cmd.Context(), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Import error: %v\n", err)
+			os.Exit(1)
+		}
+		if importDryRunFlag {
+			return
+		}
+		fmt.Printf("Imported %d rule(s). Review them, then install with:\n  ai-rules-link rules", len(imported))
+		for _, r := range imported {
+			fmt.Printf(" --rule=%s", r.Name)
+		}
+		fmt.Println()
+	},
+}
+
+func init() {
+	importCmd.Flags().StringSliceVar(&importFromFlags, "from", nil, "Instruction file(s) to import, relative to the project (default: every known file that exists)")
+	importCmd.Flags().StringVar(&importPrefixFlag, "prefix", "", "Prefix for the imported rule names (default: the project directory name)")
+	importCmd.Flags().BoolVar(&importProjectFlag, "project", false, "Write the rules to the project's .ai-rules/ directory")
+	importCmd.Flags().StringVar(&importDirFlag, "dir", "", "Write the rules to this rules directory")
+	importCmd.Flags().BoolVar(&importForceFlag, "force", false, "Overwrite existing rules with the same names")
+	importCmd.Flags().BoolVar(&importDryRunFlag, "dry-run", false, "Show the rules that would be written without writing them")
+	rootCmd.AddCommand(importCmd)
+}
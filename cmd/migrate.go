@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateModeFlag string
+var migrateForceFlag bool
+var migrateDryRunFlag bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Replace a legacy .cursorrules file with rules in .cursor/rules/",
+	Long: `migrate looks for each of your rules in the project's .cursorrules file, paragraph by
+paragraph, so a file that pastes several rules together is recognized with or without headings.
+A rule found at least 90% complete is installed as that rule and its paragraphs are dropped; the
+rest is written to .cursor/rules/projectrules.mdc. The legacy file is then moved to
+.cursorrules.bak and a report lists what matched. migrate only writes Cursor rules; use import to
+bring other tools' instruction files into your rules.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		targets, err := selectedTargets([]string{"cursor"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		var mode domain.InstallMode
+		if migrateModeFlag != "" {
+			if mode, err = domain.ParseInstallMode(migrateModeFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		manifest, err := service.LoadManifest(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		source := resolveRuleSource(os.Stderr)
+		report, err := service.MigrateCursorrules(cmd.Context(), service.MigrateOptions{
+			ProjectDir: cwd,
+			Source:     source,
+			Mode:       mode,
+			Target:     targets[0],
+			Manifest:   manifest,
+			Force:      migrateForceFlag,
+			DryRun:     migrateDryRunFlag,
+			Stdout:     os.Stdout,
+			Stderr:     os.Stderr,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migrate error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Migration report for %s (matched against %s rules):\n", service.LegacyCursorrules, source.Name)
+		for _, m := range report.Matches {
+			kind := "exact"
+			if m.Similarity < 1 {
+				kind = fmt.Sprintf("%.0f%% similar", m.Similarity*100)
+			}
+			fmt.Printf("  %-30s -> rule %s (%s)\n", m.Section, m.Rule, kind)
+		}
+		for _, s := range report.Unmatched {
+			fmt.Printf("  %-30s -> %s\n", s, report.ProjectRule)
+		}
+		if migrateDryRunFlag {
+			fmt.Printf("Dry run: nothing was changed. %s would be moved to %s.\n", service.LegacyCursorrules, report.Backup)
+			return
+		}
+		fmt.Printf("Moved %s to %s.\n", service.LegacyCursorrules, report.Backup)
+		if err := manifest.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.Flags().StringVar(&migrateModeFlag, "mode", "", "Install mode for matched rules: symlink, relsymlink, copy or hardlink (default: symlink for a rules directory, copy for embedded rules)")
+	migrateCmd.Flags().BoolVar(&migrateForceFlag, "force", false, "Overwrite an existing .cursor/rules/projectrules.mdc")
+	migrateCmd.Flags().BoolVar(&migrateDryRunFlag, "dry-run", false, "Print the migration report without changing anything")
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var newGlobsFlag []string
+var newAlwaysFlag bool
+var newDescriptionFlag string
+var newProjectFlag bool
+var newDirFlag string
+var newForceFlag bool
+
+var newCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Scaffold a new <name>rules.mdc in your rules directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dir := newDirFlag
+		switch {
+		case dir != "":
+		case newProjectFlag:
+			dir = filepath.Join(cwd, service.ProjectRulesDir)
+		default:
+			dir = userRulesDir()
+		}
+		opts := service.NewRuleOptions{
+			Name:        args[0],
+			Description: newDescriptionFlag,
+			Globs:       domain.ParseGlobs(strings.Join(newGlobsFlag, ",")),
+			AlwaysApply: newAlwaysFlag,
+			Dir:         dir,
+			Sources:     service.CandidateSources(cwd, embeddedRules),
+			Force:       newForceFlag,
+		}
+		before := service.ResolveRuleSource(cwd, embeddedRules, io.Discard)
+		path, err := service.ScaffoldRule(cmd.Context(), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s\n", path)
+		switch src := service.ResolveRuleSource(cwd, embeddedRules, io.Discard); {
+		case src.Dir != dir:
+			fmt.Printf("[ai-rules-link] Note: rules are currently read from %s, so this rule will not be found there.\n", src.Name)
+		case before.Dir != dir && before.Embedded():
+			fmt.Fprintf(os.Stderr, "[ai-rules-link] Warning: rules are now read from %s instead of the embedded rules, which it hides. Export them next to your rule with: ai-rules-link bootstrap --dest %s\n", dir, dir)
+		case before.Dir != dir:
+			fmt.Fprintf(os.Stderr, "[ai-rules-link] Warning: rules are now read from %s instead of %s, which it hides. Copy the rules you use from %s into it.\n", dir, before.Name, before.Dir)
+		}
+		fmt.Printf("Use it with: ai-rules-link rules --rule=%s\n", service.RuleNameOf(path))
+	},
+}
+
+// userRulesDir is the writable user-level rules directory: $XDG_CONFIG_HOME/ai-rules when
+// XDG_CONFIG_HOME is set, ~/ai-rules otherwise.
+func userRulesDir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "ai-rules")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "ai-rules")
+}
+
+func init() {
+	newCmd.Flags().StringArrayVar(&newGlobsFlag, "globs", nil, "File globs the rule applies to (e.g., --globs='**/*.go')")
+	newCmd.Flags().BoolVar(&newAlwaysFlag, "always", false, "Set alwaysApply: true so the rule is attached to every request")
+	newCmd.Flags().StringVar(&newDescriptionFlag, "description", "", "Rule description for the frontmatter")
+	newCmd.Flags().BoolVar(&newProjectFlag, "project", false, "Create the rule in the project's .ai-rules/ directory")
+	newCmd.Flags().StringVar(&newDirFlag, "dir", "", "Create the rule in this rules directory")
+	newCmd.Flags().BoolVar(&newForceFlag, "force", false, "Overwrite an existing rule, or shadow one with the same name in a source read later")
+	rootCmd.AddCommand(newCmd)
+}
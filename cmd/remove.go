@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var removeRuleFlags []string
+var removeTargetFlags []string
+var removeForceFlag bool
+var removePathFlags []string
+
+var removeCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove installed rule files, managed blocks and config edits recorded in .ai-rules-link.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		baseDir, _, err := installPaths()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := service.LoadManifest(baseDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		var packages []string
+		for _, p := range removePathFlags {
+			p, err := service.CleanPackagePath(p)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			packages = append(packages, p)
+		}
+		opts := service.RemoveOptions{
+			Rules:    removeRuleFlags,
+			Targets:  removeTargetFlags,
+			Packages: packages,
+			Force:    removeForceFlag,
+			Manifest: manifest,
+			Stdout:   os.Stdout,
+			Stderr:   os.Stderr,
+		}
+		n, err := service.RemoveInstalls(cmd.Context(), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Remove error: %v\n", err)
+			os.Exit(1)
+		}
+		if n == 0 {
+			fmt.Println("Nothing to remove.")
+		}
+		if err := manifest.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	removeCmd.Flags().StringSliceVar(&removeRuleFlags, "rule", nil, "Only remove files generated from these rules (default: all)")
+	removeCmd.Flags().StringSliceVar(&removeTargetFlags, "target", nil, "Only remove files installed for these targets (default: all)")
+	removeCmd.Flags().StringSliceVar(&removePathFlags, "path", nil, "Only remove files installed for these monorepo packages, e.g. --path=web (\".\" is the project root; default: all)")
+	removeCmd.Flags().BoolVar(&removeForceFlag, "force", false, "Remove copies even if they have been modified since they were installed")
+	removeCmd.Flags().BoolVar(&globalFlag, "global", false, "Remove rules installed in the home directory (~/) instead of the current directory")
+	rootCmd.AddCommand(removeCmd)
+}
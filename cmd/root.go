@@ -8,11 +8,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// version is the release this binary was built from; override with -ldflags "-X ai-rules-link/cmd.version=...".
+var version = "0.0.2"
+
 // rootCmd is the base command for ai-rules-link.
 var rootCmd = &cobra.Command{
-	Use:   "ai-rules-link",
-	Short: "A CLI to manage AI context for different tools and technologies",
-	Long:  `ai-rules-link is a tool to standardize AI-assisted development by generating context-aware prompts.`,
+	Use:     "ai-rules-link",
+	Version: version,
+	Short:   "A CLI to manage AI context for different tools and technologies",
+	Long:    `ai-rules-link is a tool to standardize AI-assisted development by generating context-aware prompts.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -2,15 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"ai-rules-link/internal/service"
 
 	"github.com/spf13/cobra"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "List all symlinks in .cursor/rules/ and their targets",
+	Short: "List the symlinks in .cursor/rules/, including the nested rules directories of monorepo packages, and their targets",
 	Run: func(cmd *cobra.Command, args []string) {
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -18,44 +25,101 @@ var statusCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		// Determine rules source
-		rulesSource := "embedded (copied/generated)"
-		xdg := os.Getenv("XDG_CONFIG_HOME")
-		if xdg != "" {
-			candidate := filepath.Join(xdg, "ai-rules")
-			if stat, err := os.Stat(candidate); err == nil && stat.IsDir() {
-				rulesSource = "$XDG_CONFIG_HOME/ai-rules: " + candidate
-			}
-		}
-		if rulesSource == "embedded (copied/generated)" {
-			home, _ := os.UserHomeDir()
-			candidate := filepath.Join(home, "ai-rules")
-			if stat, err := os.Stat(candidate); err == nil && stat.IsDir() {
-				rulesSource = "~/ai-rules: " + candidate
-			}
+		src := resolveRuleSource(io.Discard)
+		rulesSource := src.Name + ": " + src.Dir
+		if src.Embedded() {
+			rulesSource = "embedded (copied/generated)"
 		}
 		fmt.Printf("Rules source: %s\n", rulesSource)
 
-		rulesDir := filepath.Join(cwd, ".cursor", "rules")
-		entries, err := os.ReadDir(rulesDir)
+		manifest, err := service.LoadManifest(cwd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Could not read .cursor/rules/: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Symlinks in .cursor/rules/:")
-		for _, entry := range entries {
-			if entry.Type()&os.ModeSymlink != 0 {
-				linkPath := filepath.Join(rulesDir, entry.Name())
-				target, err := os.Readlink(linkPath)
-				if err != nil {
-					fmt.Printf("  %s -> [broken symlink]\n", entry.Name())
-				} else {
-					fmt.Printf("  %s -> %s\n", entry.Name(), target)
+		dirs := cursorRulesDirs(manifest)
+		for _, dir := range dirs {
+			rulesDir := filepath.Join(cwd, filepath.FromSlash(dir))
+			entries, err := os.ReadDir(rulesDir)
+			if err != nil {
+				if len(dirs) == 1 {
+					fmt.Fprintf(os.Stderr, "Could not read %s/: %v\n", dir, err)
+					os.Exit(1)
+				}
+				continue
+			}
+			fmt.Printf("Symlinks in %s/:\n", dir)
+			for _, entry := range entries {
+				if entry.Type()&os.ModeSymlink != 0 {
+					linkPath := filepath.Join(rulesDir, entry.Name())
+					target, err := os.Readlink(linkPath)
+					if err != nil {
+						fmt.Printf("  %s -> [broken symlink]\n", entry.Name())
+					} else {
+						fmt.Printf("  %s -> %s\n", entry.Name(), target)
+					}
 				}
 			}
 		}
+		printPackages(manifest)
 	},
 }
 
+// cursorRulesDirs returns .cursor/rules and the other Cursor rules directories the manifest
+// recorded installs in, such as those of monorepo packages.
+func cursorRulesDirs(m *service.Manifest) []string {
+	dirs := []string{".cursor/rules"}
+	seen := map[string]bool{dirs[0]: true}
+	var nested []string
+	for _, e := range m.Entries {
+		if e.Target != "" && e.Target != service.DefaultTarget || e.Global {
+			continue
+		}
+		dir := path.Dir(e.Path)
+		if !seen[dir] {
+			seen[dir] = true
+			nested = append(nested, dir)
+		}
+	}
+	sort.Strings(nested)
+	return append(dirs, nested...)
+}
+
+// printPackages summarizes the files the manifest recorded for each monorepo package.
+func printPackages(m *service.Manifest) {
+	files := map[string]int{}
+	rules := map[string][]string{}
+	scoped := map[string]bool{}
+	var dirs []string
+	for _, e := range m.Entries {
+		if e.Dir == "" {
+			continue
+		}
+		if files[e.Dir] == 0 {
+			dirs = append(dirs, e.Dir)
+		}
+		files[e.Dir]++
+		scoped[e.Dir] = scoped[e.Dir] || e.Scoped
+		for _, r := range e.Rules {
+			if !slices.Contains(rules[e.Dir], r) {
+				rules[e.Dir] = append(rules[e.Dir], r)
+			}
+		}
+	}
+	if len(dirs) == 0 {
+		return
+	}
+	sort.Strings(dirs)
+	fmt.Println("Packages:")
+	for _, dir := range dirs {
+		scope := service.ScopeNested
+		if scoped[dir] {
+			scope = service.ScopeGlobs
+		}
+		fmt.Printf("  %s (%s): %d files, rules %s\n", dir, scope, files[dir], strings.Join(rules[dir], ", "))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 }
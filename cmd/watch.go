@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var watchIntervalFlag time.Duration
+var watchDebounceFlag time.Duration
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep copied and consolidated rules in sync with their rule sources",
+	Long: `watch polls the rule sources recorded in .ai-rules-link.json and regenerates copies,
+consolidated files and hard links when a source rule changes. Symlinks update on their own.
+Files edited since they were installed are left alone. When .ai-rules.yaml changes, its packages
+are installed again for the targets already in the project.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		baseDir, _, err := installPaths()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		packagesPath := filepath.Join(baseDir, service.PackagesFilename)
+		packages, _ := os.ReadFile(packagesPath)
+		sync := func(ctx context.Context) error {
+			manifest, err := service.LoadManifest(baseDir)
+			if err != nil {
+				return err
+			}
+			// A changed .ai-rules.yaml installs the packages again before the files are synced.
+			changed := false
+			if data, _ := os.ReadFile(packagesPath); !globalFlag && !bytes.Equal(data, packages) {
+				packages = data
+				config, found, err := service.LoadPackages(baseDir)
+				if err != nil {
+					return err
+				}
+				if found {
+					err := service.SyncPackages(ctx, service.SyncPackagesOptions{
+						Config:   config,
+						Source:   resolveRuleSource(os.Stderr),
+						Manifest: manifest,
+						Stdout:   os.Stdout,
+						Stderr:   os.Stderr,
+					})
+					if err != nil {
+						return err
+					}
+					changed = true
+				}
+			}
+			n, err := service.SyncInstalls(ctx, service.SyncOptions{
+				Manifest: manifest,
+				Embedded: embeddedRules,
+				Stdout:   os.Stdout,
+				Stderr:   os.Stderr,
+			})
+			if err != nil {
+				return err
+			}
+			if n == 0 && !changed {
+				return nil
+			}
+			return manifest.Save()
+		}
+		paths := func() []string {
+			manifest, err := service.LoadManifest(baseDir)
+			if err != nil {
+				return nil
+			}
+			return service.WatchPaths(manifest)
+		}
+
+		if err := sync(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Sync error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Watching %d path(s) for rule changes. Press Ctrl+C to stop.\n", len(paths()))
+		err = service.Watch(ctx, service.WatchOptions{
+			Paths:    paths,
+			Interval: watchIntervalFlag,
+			Debounce: watchDebounceFlag,
+			OnChange: sync,
+			Stderr:   os.Stderr,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchIntervalFlag, "interval", time.Second, "How often to poll rule sources for changes")
+	watchCmd.Flags().DurationVar(&watchDebounceFlag, "debounce", 500*time.Millisecond, "How long changes must settle before files are regenerated")
+	watchCmd.Flags().BoolVar(&globalFlag, "global", false, "Watch rules installed in the home directory (~/) instead of the current directory")
+	rootCmd.AddCommand(watchCmd)
+}
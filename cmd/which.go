@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which <rule>[#section...]",
+	Short: "Show every location checked for a rule, in lookup order, and which one is used",
+	Long: `which prints the locations rules are looked up in, in order: the project's .ai-rules/,
+$XDG_CONFIG_HOME/ai-rules, ~/ai-rules and the embedded rules. For each it shows whether the rule
+file exists and a hash of its content. Rules are installed from the first location that exists, so
+a rule missing there is not installed even if a later location has it. Only these standard
+locations are searched; no other rules directory can be configured. Sections selected with
+name#section are ignored: they come from the same rule file.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rule := args[0]
+		name, _ := domain.ParseRuleSpec(rule)
+		projectDir := ""
+		if !globalFlag {
+			cwd, err := os.Getwd()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			projectDir = cwd
+		}
+		candidates := service.WhichRule(projectDir, embeddedRules, rule)
+		fmt.Printf("Lookup order for %s:\n", service.RuleFilename(name))
+		var used *service.RuleCandidate
+		var elsewhere []string
+		for i, c := range candidates {
+			state := "no such directory"
+			switch {
+			case c.Exists:
+				state = "found  sha256:" + c.SHA256[:12]
+			case c.Available:
+				state = "missing"
+			}
+			marker := ""
+			if c.Selected {
+				marker = "  <- rules are installed from here"
+				used = &candidates[i]
+			} else if c.Exists {
+				elsewhere = append(elsewhere, c.Source.Name)
+			}
+			fmt.Printf("  %d. %-26s %s\n     %s%s\n", i+1, c.Source.Name, c.Path, state, marker)
+		}
+		switch {
+		case used.Exists:
+			fmt.Printf("--rule=%s installs %s from %s.\n", rule, used.Path, used.Source.Name)
+		case len(elsewhere) > 0:
+			fmt.Printf("%s is in use but has no %s, so --rule=%s would be skipped. It exists in: %s; copy it into %s or remove that directory.\n",
+				used.Source.Name, service.RuleFilename(name), rule, strings.Join(elsewhere, ", "), used.Source.Name)
+		default:
+			fmt.Printf("No location has %s.\n", service.RuleFilename(name))
+		}
+	},
+}
+
+func init() {
+	whichCmd.Flags().BoolVar(&globalFlag, "global", false, "Resolve as for --global installs, without the project's .ai-rules/")
+	rootCmd.AddCommand(whichCmd)
+}
@@ -0,0 +1,165 @@
+// Package detect inspects a project for the technologies it uses and maps them to rules.
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Finding is one piece of evidence that a project uses the technology a rule covers.
+type Finding struct {
+	// Rule is the rule name the evidence points to, e.g. "go".
+	Rule string
+	// Evidence describes what was found, e.g. "go.mod" or "web/package.json depends on next".
+	Evidence string
+	// Dir is the directory the evidence was found in, relative to the project root; "." is the root.
+	Dir string
+}
+
+// Detector recognizes one technology from the files in a directory.
+type Detector struct {
+	Rule string
+	// Detect returns evidence found in dir; rel is dir relative to the project root, for messages.
+	Detect func(dir, rel string) []string
+	// Globs match the files the technology's rule is about, for rules scoped by language.
+	Globs []string
+}
+
+// Detectors are run by Detect in order.
+var Detectors = []Detector{
+	{Rule: "go", Detect: files("go.mod", "go.work"), Globs: []string{"**/*.go", "**/go.mod"}},
+	{Rule: "nextjs", Detect: nextjs, Globs: []string{"**/*.ts", "**/*.tsx", "**/*.js", "**/*.jsx"}},
+	{Rule: "python", Detect: files("pyproject.toml", "requirements.txt", "setup.py", "Pipfile"), Globs: []string{"**/*.py", "**/pyproject.toml"}},
+	{Rule: "docker", Detect: files("Dockerfile", "compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"), Globs: []string{"**/Dockerfile", "**/*.dockerfile", "**/compose*.y*ml", "**/docker-compose*.y*ml"}},
+}
+
+// Globs returns the file globs for a technology or language, e.g. ["**/*.go", "**/go.mod"] for
+// "go", or nil when it is unknown.
+func Globs(language string) []string {
+	language = strings.ToLower(language)
+	if alias, ok := languageAliases[language]; ok {
+		language = alias
+	}
+	for _, d := range Detectors {
+		if d.Rule == language {
+			return d.Globs
+		}
+	}
+	return nil
+}
+
+// languageAliases maps other names a rule's language metadata may use to detector rules.
+var languageAliases = map[string]string{
+	"golang":     "go",
+	"next":       "nextjs",
+	"typescript": "nextjs",
+	"javascript": "nextjs",
+	"py":         "python",
+}
+
+// skipDirs are never searched.
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true, "testdata": true}
+
+// MaxDepth is how many directory levels below the project root Detect searches, enough for the
+// packages of a monorepo laid out as web/ or services/api/.
+const MaxDepth = 2
+
+// Detect runs every detector on the project root and the directories up to MaxDepth below it, so
+// the packages of a monorepo are found too. Hidden directories are skipped.
+func Detect(root string) ([]Finding, error) {
+	dirs := []string{"."}
+	for i := 0; i < len(dirs); i++ {
+		rel := dirs[i]
+		if rel != "." && strings.Count(rel, "/") >= MaxDepth-1 {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(root, rel))
+		if err != nil {
+			if rel == "." {
+				return nil, err
+			}
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") && !skipDirs[e.Name()] {
+				dirs = append(dirs, path.Join(rel, e.Name()))
+			}
+		}
+	}
+	var findings []Finding
+	for _, d := range Detectors {
+		for _, rel := range dirs {
+			for _, evidence := range d.Detect(filepath.Join(root, rel), rel) {
+				findings = append(findings, Finding{Rule: d.Rule, Evidence: evidence, Dir: rel})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// Rules returns the distinct rules in findings, in detector order.
+func Rules(findings []Finding) []string {
+	var rules []string
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			rules = append(rules, f.Rule)
+		}
+	}
+	return rules
+}
+
+// RulesByDir groups the distinct rules in findings by the directory they were found in, which is
+// how a monorepo's packages map to rules.
+func RulesByDir(findings []Finding) map[string][]string {
+	byDir := map[string][]Finding{}
+	for _, f := range findings {
+		byDir[f.Dir] = append(byDir[f.Dir], f)
+	}
+	rules := make(map[string][]string, len(byDir))
+	for dir, fs := range byDir {
+		rules[dir] = Rules(fs)
+	}
+	return rules
+}
+
+// files returns a detector body that reports which of the given files exist.
+func files(names ...string) func(dir, rel string) []string {
+	return func(dir, rel string) []string {
+		var found []string
+		for _, name := range names {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+				found = append(found, filepath.ToSlash(filepath.Join(rel, name)))
+			}
+		}
+		return found
+	}
+}
+
+// nextjs reports a package.json that depends on next, or a Next.js config file.
+func nextjs(dir, rel string) []string {
+	found := files("next.config.js", "next.config.mjs", "next.config.ts")(dir, rel)
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return found
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return found
+	}
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		if version, ok := deps["next"]; ok {
+			evidence := filepath.ToSlash(filepath.Join(rel, "package.json")) + " depends on next " + version
+			found = append([]string{evidence}, found...)
+			break
+		}
+	}
+	return found
+}
@@ -0,0 +1,57 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(root, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	write("go.mod", "module example.com/app\n")
+	write("Dockerfile", "FROM scratch\n")
+	write("web/package.json", `{"dependencies": {"react": "18.0.0"}, "devDependencies": {"next": "^14.2.0"}}`)
+	write("tools/package.json", `{"dependencies": {"express": "4"}}`)
+	write("services/api/requirements.txt", "flask\n")
+	write("services/api/deep/x/Pipfile", "")
+	write("node_modules/x/requirements.txt", "")
+	write(".venv/requirements.txt", "")
+
+	findings, err := Detect(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Finding{
+		{Rule: "go", Evidence: "go.mod", Dir: "."},
+		{Rule: "nextjs", Evidence: "web/package.json depends on next ^14.2.0", Dir: "web"},
+		{Rule: "python", Evidence: "services/api/requirements.txt", Dir: "services/api"},
+		{Rule: "docker", Evidence: "Dockerfile", Dir: "."},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("got %+v, want %+v", findings, want)
+	}
+	if got := Rules(append(findings, Finding{Rule: "go", Evidence: "go.work"})); !reflect.DeepEqual(got, []string{"go", "nextjs", "python", "docker"}) {
+		t.Errorf("unexpected rules: %v", got)
+	}
+	if got, want := RulesByDir(findings), map[string][]string{".": {"go", "docker"}, "web": {"nextjs"}, "services/api": {"python"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RulesByDir: got %v, want %v", got, want)
+	}
+}
+
+func TestGlobs(t *testing.T) {
+	if got := Globs("Golang"); !reflect.DeepEqual(got, []string{"**/*.go", "**/go.mod"}) {
+		t.Errorf("unexpected globs for golang: %v", got)
+	}
+	if got := Globs("python"); len(got) == 0 || got[0] != "**/*.py" {
+		t.Errorf("unexpected globs for python: %v", got)
+	}
+	if got := Globs("cobol"); got != nil {
+		t.Errorf("expected no globs for an unknown language, got %v", got)
+	}
+}
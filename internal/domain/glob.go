@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// GlobBaseDir returns the literal directory a glob is rooted at, e.g. "web/**/*.tsx" -> "web".
+// Globs that can match anywhere, such as "*.go" or "**/*.go", return "".
+func GlobBaseDir(glob string) string {
+	glob = strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(glob, "./")), "/")
+	segments := strings.Split(glob, "/")
+	var literal []string
+	for _, s := range segments[:len(segments)-1] {
+		if strings.ContainsAny(s, "*?[{") {
+			break
+		}
+		literal = append(literal, s)
+	}
+	return strings.Join(literal, "/")
+}
+
+// CommonGlobDir returns the deepest directory every glob is rooted under, or "" if any glob can match
+// outside a subdirectory.
+func CommonGlobDir(globs []string) string {
+	if len(globs) == 0 {
+		return ""
+	}
+	common := strings.Split(GlobBaseDir(globs[0]), "/")
+	for _, g := range globs[1:] {
+		dir := strings.Split(GlobBaseDir(g), "/")
+		n := 0
+		for n < len(common) && n < len(dir) && common[n] == dir[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return strings.Join(common, "/")
+}
+
+// ScopeGlob roots glob at dir. A glob without a slash matches a file name at any depth, as in
+// Cursor, so it stays free to match anywhere under dir: "*.go" in "api" becomes "api/**/*.go".
+func ScopeGlob(glob, dir string) string {
+	glob = strings.TrimPrefix(strings.TrimPrefix(glob, "./"), "/")
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	return path.Join(dir, glob)
+}
+
+// MatchGlob reports whether name, a slash-separated path relative to the project, matches glob the
+// way Cursor matches rule globs: "**" matches any number of directories, "*", "?" and "[...]" stay
+// within one path segment, "{a,b}" lists alternatives, and a glob without a slash matches the file
+// name at any depth.
+func MatchGlob(glob, name string) bool {
+	return CompileGlob(glob).Match(name)
+}
+
+// Glob is a glob prepared for matching many paths, as when a whole project is checked.
+type Glob struct {
+	alternatives [][]string // the segments of each brace expansion
+}
+
+// CompileGlob prepares glob for matching with the semantics of MatchGlob.
+func CompileGlob(glob string) Glob {
+	return compileGlob(glob, true)
+}
+
+// CompilePathGlob prepares glob like CompileGlob, except that a glob without a slash only matches
+// at the top level, as for anchored .gitignore patterns.
+func CompilePathGlob(glob string) Glob {
+	return compileGlob(glob, false)
+}
+
+func compileGlob(glob string, anyDepth bool) Glob {
+	glob = strings.TrimPrefix(strings.TrimPrefix(glob, "./"), "/")
+	var g Glob
+	for _, alt := range expandBraces(glob) {
+		if anyDepth && !strings.Contains(alt, "/") {
+			alt = "**/" + alt
+		}
+		g.alternatives = append(g.alternatives, strings.Split(alt, "/"))
+	}
+	return g
+}
+
+// Match reports whether name, a slash-separated relative path, matches the glob.
+func (g Glob) Match(name string) bool {
+	segments := strings.Split(strings.TrimPrefix(path.Clean("/"+name), "/"), "/")
+	for _, alt := range g.alternatives {
+		if matchSegments(alt, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against glob segments, letting "**" stand for zero or more.
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// expandBraces expands the first "{a,b}" group in glob, and recursively the rest, into plain globs.
+func expandBraces(glob string) []string {
+	start := strings.IndexByte(glob, '{')
+	if start < 0 {
+		return []string{glob}
+	}
+	depth, last := 0, start+1
+	var options []string
+	for i := start; i < len(glob); i++ {
+		switch glob[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				options = append(options, glob[last:i])
+				var out []string
+				for _, o := range options {
+					out = append(out, expandBraces(glob[:start]+o+glob[i+1:])...)
+				}
+				return out
+			}
+		case ',':
+			if depth == 1 {
+				options = append(options, glob[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return []string{glob} // unbalanced braces are matched literally
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "testing"
+
+func TestGlobBaseDir(t *testing.T) {
+	cases := map[string]string{
+		"web/**/*.tsx":        "web",
+		"./services/api/*.go": "services/api",
+		"*.go":                "",
+		"**/*.go":             "",
+		"src/{a,b}/*.ts":      "src",
+		"Dockerfile":          "",
+	}
+	for glob, want := range cases {
+		if got := GlobBaseDir(glob); got != want {
+			t.Errorf("GlobBaseDir(%q) = %q, want %q", glob, got, want)
+		}
+	}
+}
+
+func TestCommonGlobDir(t *testing.T) {
+	cases := []struct {
+		globs []string
+		want  string
+	}{
+		{[]string{"web/app/**/*.tsx", "web/lib/*.ts"}, "web"},
+		{[]string{"web/**/*.tsx", "**/*.ts"}, ""},
+		{[]string{"services/api/**"}, "services/api"},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := CommonGlobDir(c.globs); got != c.want {
+			t.Errorf("CommonGlobDir(%v) = %q, want %q", c.globs, got, c.want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		glob, name string
+		want       bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/root/main.go", true},
+		{"*.go", "internal/x.go", true},
+		{"*.go", "internal/x.ts", false},
+		{"src/*.ts", "src/a.ts", true},
+		{"src/*.ts", "src/lib/a.ts", false},
+		{"web/**", "web/app/page.tsx", true},
+		{"web/**", "website/page.tsx", false},
+		{"services/api/**/*.go", "services/api/main.go", true},
+		{"**/*.{ts,tsx}", "web/app/page.tsx", true},
+		{"**/*.{ts,tsx}", "web/app/page.js", false},
+		{"**/compose*.y*ml", "deploy/compose.prod.yaml", true},
+		{"./Dockerfile", "Dockerfile", true},
+		{"Dockerfile", "svc/Dockerfile", true},
+		{"[ab].md", "docs/a.md", true},
+	}
+	for _, c := range cases {
+		if got := MatchGlob(c.glob, c.name); got != c.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", c.glob, c.name, got, c.want)
+		}
+	}
+}
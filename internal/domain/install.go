@@ -0,0 +1,37 @@
+package domain
+
+import "fmt"
+
+// InstallMode controls how a rule file ends up in a project's rules directory.
+type InstallMode string
+
+const (
+	// ModeSymlink links each rule to its canonical file with an absolute path.
+	ModeSymlink InstallMode = "symlink"
+	// ModeRelSymlink links each rule with a path relative to the destination, so links survive a clone.
+	ModeRelSymlink InstallMode = "relsymlink"
+	// ModeCopy writes a plain copy of each rule.
+	ModeCopy InstallMode = "copy"
+	// ModeHardlink hard links each rule to its canonical file.
+	ModeHardlink InstallMode = "hardlink"
+	// ModeConsolidate merges all selected rules into a single file.
+	ModeConsolidate InstallMode = "consolidate"
+)
+
+// InstallModes lists every supported install mode in display order.
+var InstallModes = []InstallMode{ModeSymlink, ModeRelSymlink, ModeCopy, ModeHardlink, ModeConsolidate}
+
+// ParseInstallMode validates a mode name as given on the command line.
+func ParseInstallMode(s string) (InstallMode, error) {
+	for _, m := range InstallModes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown install mode %q (want one of symlink, relsymlink, copy, hardlink, consolidate)", s)
+}
+
+// IsLink reports whether the mode points back at the canonical file instead of writing new content.
+func (m InstallMode) IsLink() bool {
+	return m == ModeSymlink || m == ModeRelSymlink || m == ModeHardlink
+}
@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PatchOp is what a Patch does to the part of a rule it targets.
+type PatchOp string
+
+const (
+	// PatchReplace replaces the target. A directive keeps its number and a section its heading
+	// unless the new text brings its own.
+	PatchReplace PatchOp = "replace"
+	// PatchRemove deletes the target. Later directives keep their numbers.
+	PatchRemove PatchOp = "remove"
+	// PatchAppend adds text to the end of the target: continuation lines of a directive, or the end
+	// of a section's own text, before its subsections.
+	PatchAppend PatchOp = "append"
+	// PatchInsertAfter adds text after the target: a new list item after a directive, or a block
+	// after a section and its subsections.
+	PatchInsertAfter PatchOp = "insertAfter"
+)
+
+// Patch changes one part of a rule's body: a numbered directive, a section, a directive within a
+// section, or the whole body when neither is set.
+type Patch struct {
+	Rule string
+	// Heading is the anchor or text of the section to change, or of the section holding Directive.
+	// In rules without headings it may name a bold label, as with ExtractSections; a labelled
+	// directive is patched as that directive.
+	Heading string
+	// Directive is the number of an unindented numbered list item, e.g. 7 for "7.  **Explain on
+	// Request:** ...". Numbers are those of the rule as published; patches do not renumber.
+	Directive int
+	// Expect is text the target must contain, so that a patch fails rather than changing the wrong
+	// part once the rule changes upstream.
+	Expect string
+	Op     PatchOp
+	// Text is the content replace, append and insertAfter add.
+	Text string
+}
+
+// String names the patch's target, e.g. "base directive 7" or "go section concurrency".
+func (p Patch) String() string {
+	s := p.Rule
+	if p.Heading != "" {
+		s += " section " + Anchor(p.Heading)
+	}
+	if p.Directive > 0 {
+		s += " directive " + strconv.Itoa(p.Directive)
+	}
+	return s
+}
+
+// ApplyPatches applies, in order, the patches for r. Rules without patches are returned unchanged;
+// patched rules no longer match any file on disk.
+func ApplyPatches(r Rule, patches []Patch) (Rule, error) {
+	body := r.Body
+	patched := false
+	for _, p := range patches {
+		if !strings.EqualFold(p.Rule, r.Name) {
+			continue
+		}
+		var err error
+		if body, err = applyPatch(body, p); err != nil {
+			return r, fmt.Errorf("%s %s no longer applies: %w", p.Op, p, err)
+		}
+		patched = true
+	}
+	if !patched {
+		return r, nil
+	}
+	r.Body = body
+	r.Raw = r.MDC()
+	r.SourcePath = ""
+	return r, nil
+}
+
+var directivePattern = regexp.MustCompile(`^(\d+)\.[ \t]+`)
+
+// mdLine is a line of markdown with its line ending, and its heading level when it is a heading.
+type mdLine struct {
+	text    string
+	heading string
+	level   int
+}
+
+func (l mdLine) blank() bool {
+	return strings.TrimSpace(l.text) == ""
+}
+
+func applyPatch(body string, p Patch) (string, error) {
+	// Sections are found like ExtractSections finds them, by bold labels in rules without headings.
+	labels := SectionLevel(body) == 0
+	var lines []mdLine
+	scanLines(body, labels, func(line, heading string, level int) {
+		lines = append(lines, mdLine{text: line, heading: heading, level: level})
+	})
+	start, end := 0, len(lines)
+	if p.Heading != "" {
+		var anchors []string
+		start = -1
+		for i, l := range lines {
+			if l.level == 0 {
+				continue
+			}
+			anchors = append(anchors, Anchor(l.heading))
+			if start < 0 && Anchor(l.heading) == Anchor(p.Heading) {
+				start = i
+			}
+		}
+		if start < 0 {
+			return "", fmt.Errorf("no section %s (sections: %s)", Anchor(p.Heading), strings.Join(anchors, ", "))
+		}
+		for end = start + 1; end < len(lines); end++ {
+			if lines[end].level > 0 && lines[end].level <= lines[start].level {
+				break
+			}
+		}
+		// A labelled directive, "5.  **Concurrency:** ...", is patched as that directive.
+		if m := directivePattern.FindStringSubmatch(lines[start].text); labels && m != nil && p.Directive == 0 {
+			p.Directive, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	from, to := start, end
+	marker := ""
+	if p.Directive > 0 {
+		from = -1
+		var numbers []string
+		for i := start; i < end; i++ {
+			m := directivePattern.FindStringSubmatch(lines[i].text)
+			if m == nil {
+				continue
+			}
+			numbers = append(numbers, m[1])
+			if n, _ := strconv.Atoi(m[1]); n != p.Directive {
+				continue
+			}
+			if from >= 0 {
+				return "", fmt.Errorf("directive %d appears more than once; add a heading to pick one", p.Directive)
+			}
+			from, marker = i, m[0]
+		}
+		if from < 0 {
+			return "", fmt.Errorf("no directive %d (directives: %s)", p.Directive, strings.Join(numbers, ", "))
+		}
+		for to = from + 1; to < end; to++ {
+			l := lines[to]
+			if !l.blank() && (l.level > 0 || l.text[0] != ' ' && l.text[0] != '\t') {
+				break
+			}
+		}
+	}
+	for to > from && lines[to-1].blank() {
+		to--
+	}
+
+	var target strings.Builder
+	for _, l := range lines[from:to] {
+		target.WriteString(l.text)
+	}
+	if !strings.Contains(target.String(), p.Expect) {
+		return "", fmt.Errorf("it does not contain %q", p.Expect)
+	}
+
+	text := patchLines(p.Text)
+	var out []string
+	switch p.Op {
+	case PatchReplace:
+		switch {
+		case p.Directive > 0:
+			if len(text) > 0 && !directivePattern.MatchString(text[0]) {
+				text[0] = marker + text[0]
+			}
+		case p.Heading != "":
+			if len(text) > 0 && !isHeading(strings.TrimRight(text[0], "\n"), labels) {
+				text = append([]string{lines[from].text, "\n"}, text...)
+			}
+		}
+		out = splice(lines, from, to, text)
+	case PatchRemove:
+		if p.Directive == 0 && p.Heading == "" {
+			return "", fmt.Errorf("remove needs a directive or a heading")
+		}
+		for from == 0 || lines[from-1].blank() {
+			if to >= len(lines) || !lines[to].blank() {
+				break
+			}
+			to++
+		}
+		out = splice(lines, from, to, nil)
+	case PatchAppend:
+		if p.Directive > 0 {
+			indent := strings.Repeat(" ", len(marker))
+			for i, t := range text {
+				if strings.TrimSpace(t) != "" && t[0] != ' ' && t[0] != '\t' {
+					text[i] = indent + t
+				}
+			}
+			out = splice(lines, to, to, text)
+			break
+		}
+		at := to
+		for i := from + 1; p.Heading != "" && i < to; i++ {
+			if lines[i].level > 0 {
+				at = i
+				break
+			}
+		}
+		for at > from && lines[at-1].blank() {
+			at--
+		}
+		out = splice(lines, at, at, separated(lines, at, text))
+	case PatchInsertAfter:
+		if p.Directive > 0 {
+			out = splice(lines, to, to, text)
+			break
+		}
+		out = splice(lines, to, to, separated(lines, to, text))
+	default:
+		return "", fmt.Errorf("unknown operation %q", p.Op)
+	}
+	return strings.Join(out, ""), nil
+}
+
+// patchLines splits a patch's text into lines, each ending in a newline.
+func patchLines(text string) []string {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.SplitAfter(text+"\n", "\n")[:strings.Count(text, "\n")+1]
+}
+
+// separated surrounds text with the blank lines needed to keep it a block of its own at line at.
+func separated(lines []mdLine, at int, text []string) []string {
+	if at > 0 && !lines[at-1].blank() {
+		text = append([]string{"\n"}, text...)
+	}
+	if at < len(lines) && !lines[at].blank() {
+		text = append(text, "\n")
+	}
+	return text
+}
+
+// splice returns the text of lines with lines[from:to] replaced by text.
+func splice(lines []mdLine, from, to int, text []string) []string {
+	out := make([]string, 0, len(lines)+len(text))
+	for _, l := range lines[:from] {
+		out = append(out, l.text)
+	}
+	out = append(out, text...)
+	for _, l := range lines[to:] {
+		out = append(out, l.text)
+	}
+	return out
+}
+
+// isHeading reports whether line is a markdown heading or, with labels set, a bold label.
+func isHeading(line string, labels bool) bool {
+	return headingPattern.MatchString(line) || labels && labelPattern.MatchString(line)
+}
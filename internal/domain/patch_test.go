@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+const patchBody = `Intro.
+
+**Core Directives:**
+
+1.  **Conventions:** Follow them.
+    Why: Consistency.
+2.  **Dependencies:** Ask first.
+    Why: Less bloat.
+3.  **Explain on Request:** Only when asked.
+
+## Go
+
+Use gofmt.
+
+### Errors
+
+Wrap them.
+
+## Testing
+
+Table tests.
+`
+
+func TestApplyPatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch Patch
+		want  string // the changed part of patchBody, as old => new
+		err   string
+	}{
+		{
+			name:  "replace directive keeps its number",
+			patch: Patch{Directive: 3, Expect: "Explain on Request", Op: PatchReplace, Text: "**Explain Briefly:** One line.\n"},
+			want:  "3.  **Explain on Request:** Only when asked.\n => 3.  **Explain Briefly:** One line.\n",
+		},
+		{
+			name:  "remove directive",
+			patch: Patch{Directive: 2, Op: PatchRemove},
+			want:  "2.  **Dependencies:** Ask first.\n    Why: Less bloat.\n => ",
+		},
+		{
+			name:  "append to directive",
+			patch: Patch{Directive: 1, Op: PatchAppend, Text: "Example: camelCase."},
+			want:  "    Why: Consistency.\n =>     Why: Consistency.\n    Example: camelCase.\n",
+		},
+		{
+			name:  "insert after directive",
+			patch: Patch{Directive: 3, Op: PatchInsertAfter, Text: "4.  **Tests:** Always."},
+			want:  "Only when asked.\n => Only when asked.\n4.  **Tests:** Always.\n",
+		},
+		{
+			name:  "replace section keeps its heading",
+			patch: Patch{Heading: "Testing", Op: PatchReplace, Text: "Use testify."},
+			want:  "## Testing\n\nTable tests.\n => ## Testing\n\nUse testify.\n",
+		},
+		{
+			name:  "append to section before its subsections",
+			patch: Patch{Heading: "go", Op: PatchAppend, Text: "Use go vet."},
+			want:  "Use gofmt.\n\n => Use gofmt.\n\nUse go vet.\n\n",
+		},
+		{
+			name:  "insert after section and its subsections",
+			patch: Patch{Heading: "go", Op: PatchInsertAfter, Text: "## Style\n\nShort names."},
+			want:  "Wrap them.\n\n## Testing => Wrap them.\n\n## Style\n\nShort names.\n\n## Testing",
+		},
+		{
+			name:  "remove section",
+			patch: Patch{Heading: "errors", Op: PatchRemove},
+			want:  "### Errors\n\nWrap them.\n\n => ",
+		},
+		{
+			name:  "append to rule",
+			patch: Patch{Op: PatchAppend, Text: "Be brief."},
+			want:  "Table tests.\n => Table tests.\n\nBe brief.\n",
+		},
+		{
+			name:  "missing directive",
+			patch: Patch{Directive: 9, Op: PatchRemove},
+			err:   "no longer applies: no directive 9 (directives: 1, 2, 3)",
+		},
+		{
+			name:  "directive outside the section",
+			patch: Patch{Heading: "testing", Directive: 1, Op: PatchRemove},
+			err:   "no directive 1",
+		},
+		{
+			name:  "changed upstream",
+			patch: Patch{Directive: 3, Expect: "Explain Always", Op: PatchRemove},
+			err:   `remove base directive 3 no longer applies: it does not contain "Explain Always"`,
+		},
+		{
+			name:  "missing section",
+			patch: Patch{Heading: "python", Op: PatchAppend, Text: "x"},
+			err:   "no section python (sections: go, errors, testing)",
+		},
+	}
+	r, _ := ParseRule("base", []byte("---\ndescription: Base\nglobs:\nalwaysApply: true\n---\n\n"+patchBody))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch.Rule = "base"
+			got, err := ApplyPatches(r, []Patch{tt.patch})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			old, repl, _ := strings.Cut(tt.want, " => ")
+			if want := strings.Replace(patchBody, old, repl, 1); got.Body != want {
+				t.Errorf("body:\n%s\nwant:\n%s", got.Body, want)
+			}
+			if got.SourcePath != "" || !strings.HasSuffix(string(got.Raw), got.Body) {
+				t.Error("patched rule still points at its source")
+			}
+		})
+	}
+
+	r.SourcePath = "/rules/gorules.mdc"
+	if got, err := ApplyPatches(r, []Patch{{Rule: "go", Op: PatchAppend, Text: "x"}}); err != nil || got.SourcePath == "" {
+		t.Errorf("a rule without patches was changed: %v", err)
+	}
+}
+
+func TestApplyPatches_Labels(t *testing.T) {
+	const body = "**Go-Specific Instructions:**\n\n1.  **Formatting:** Use gofmt.\n2.  **Concurrency:** Use channels.\n    Why: Safety.\n3.  **Modules:** Tidy them.\n"
+	tests := []struct {
+		name  string
+		patch Patch
+		want  string // the changed part of body, as old => new
+	}{
+		{
+			name:  "replace labelled directive keeps its number",
+			patch: Patch{Heading: "concurrency", Expect: "channels", Op: PatchReplace, Text: "**Concurrency:** Use errgroup."},
+			want:  "2.  **Concurrency:** Use channels.\n    Why: Safety.\n => 2.  **Concurrency:** Use errgroup.\n",
+		},
+		{
+			name:  "append to labelled directive",
+			patch: Patch{Heading: "Concurrency", Op: PatchAppend, Text: "Prefer errgroup."},
+			want:  "    Why: Safety.\n =>     Why: Safety.\n    Prefer errgroup.\n",
+		},
+		{
+			name:  "remove labelled directive",
+			patch: Patch{Heading: "concurrency", Op: PatchRemove},
+			want:  "2.  **Concurrency:** Use channels.\n    Why: Safety.\n => ",
+		},
+		{
+			name:  "directive within a label's section",
+			patch: Patch{Heading: "go-specific-instructions", Directive: 3, Op: PatchRemove},
+			want:  "3.  **Modules:** Tidy them.\n => ",
+		},
+	}
+	r, _ := ParseRule("go", []byte("---\ndescription: Go\nglobs:\nalwaysApply: true\n---\n\n"+body))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.patch.Rule = "go"
+			got, err := ApplyPatches(r, []Patch{tt.patch})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			old, repl, _ := strings.Cut(tt.want, " => ")
+			if want := strings.Replace(body, old, repl, 1); got.Body != want {
+				t.Errorf("body:\n%s\nwant:\n%s", got.Body, want)
+			}
+		})
+	}
+}
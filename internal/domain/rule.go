@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rule is a parsed .mdc rule file: Cursor-style frontmatter followed by a markdown body.
+type Rule struct {
+	// Name is the rule name as passed to --rule, e.g. "go" for gorules.mdc.
+	Name        string
+	Description string
+	Globs       []string
+	AlwaysApply bool
+	// Extra holds frontmatter keys other than description, globs and alwaysApply.
+	Extra map[string]string
+	// Body is the markdown after the frontmatter, with leading blank lines removed.
+	Body string
+	// Raw is the file content exactly as read.
+	Raw []byte
+	// SourcePath is the file the rule was read from, or "" for embedded rules.
+	SourcePath string
+}
+
+// Activation is how a tool decides to attach a rule to a request.
+type Activation string
+
+const (
+	// ActivationAlways attaches the rule to every request.
+	ActivationAlways Activation = "always"
+	// ActivationGlob attaches the rule when a file matching its globs is involved.
+	ActivationGlob Activation = "glob"
+	// ActivationModelDecision lets the model attach the rule based on its description.
+	ActivationModelDecision Activation = "model_decision"
+	// ActivationManual attaches the rule only when it is mentioned explicitly.
+	ActivationManual Activation = "manual"
+)
+
+// Activation derives the rule's activation from its frontmatter the way Cursor does.
+func (r Rule) Activation() Activation {
+	switch {
+	case r.AlwaysApply:
+		return ActivationAlways
+	case len(r.Globs) > 0:
+		return ActivationGlob
+	case r.Description != "":
+		return ActivationModelDecision
+	default:
+		return ActivationManual
+	}
+}
+
+// MDC renders the rule as an .mdc file from its fields, for rules changed after they were parsed.
+func (r Rule) MDC() []byte {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "description: %s\n", FrontmatterValue(r.Description))
+	sb.WriteString(strings.TrimSpace("globs: "+JoinGlobs(r.Globs)) + "\n")
+	fmt.Fprintf(&sb, "alwaysApply: %t\n", r.AlwaysApply)
+	keys := make([]string, 0, len(r.Extra))
+	for k := range r.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s: %s\n", k, FrontmatterValue(r.Extra[k]))
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(r.Body)
+	return []byte(sb.String())
+}
+
+// ScopeRule limits r to the files under dir, a project subdirectory: its globs are rooted at dir,
+// and a rule that applied everywhere applies to everything under dir instead. Rules attached by
+// description or by hand cannot be limited to files, so their description names dir. The result
+// no longer matches any file on disk.
+func ScopeRule(r Rule, dir string) Rule {
+	switch r.Activation() {
+	case ActivationAlways:
+		r.AlwaysApply = false
+		r.Globs = []string{dir + "/**"}
+	case ActivationGlob:
+		globs := make([]string, len(r.Globs))
+		for i, g := range r.Globs {
+			globs[i] = ScopeGlob(g, dir)
+		}
+		r.Globs = globs
+	default:
+		r.Description = fmt.Sprintf("%s (for %s/)", strings.TrimSpace(r.Description), dir)
+	}
+	r.Raw = r.MDC()
+	r.SourcePath = ""
+	return r
+}
+
+// LimitToGlobs turns an always-applied rule into one attached to the files matching globs. Like
+// ScopeRule, the result no longer matches any file on disk.
+func LimitToGlobs(r Rule, globs []string) Rule {
+	r.AlwaysApply = false
+	r.Globs = globs
+	r.Raw = r.MDC()
+	r.SourcePath = ""
+	return r
+}
+
+// SectionsKey is the frontmatter key recording the sections a rule was cut down to.
+const SectionsKey = "sections"
+
+// ParseRuleSpec splits a rule selection such as "go#concurrency#error-handling" into the rule name and the
+// anchors of the sections to take; a plain name selects the whole rule.
+func ParseRuleSpec(spec string) (name string, sections []string) {
+	parts := strings.Split(spec, "#")
+	for _, s := range parts[1:] {
+		if s = strings.TrimSpace(s); s != "" {
+			sections = append(sections, s)
+		}
+	}
+	return parts[0], sections
+}
+
+// SelectSections cuts r down to the sections with the given heading anchors, see ExtractSections,
+// and records them under SectionsKey. The result no longer matches any file on disk.
+func SelectSections(r Rule, anchors []string) (Rule, error) {
+	body, err := ExtractSections(r.Body, anchors)
+	if err != nil {
+		return r, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	extra := make(map[string]string, len(r.Extra)+1)
+	for k, v := range r.Extra {
+		extra[k] = v
+	}
+	taken := make([]string, len(anchors))
+	for i, a := range anchors {
+		taken[i] = Anchor(a)
+	}
+	extra[SectionsKey] = strings.Join(taken, ", ")
+	r.Extra = extra
+	r.Body = body
+	r.Raw = r.MDC()
+	r.SourcePath = ""
+	return r, nil
+}
+
+// ParseRule parses the content of a rule file. Content without frontmatter is treated as an
+// always-applied rule whose body is the whole file.
+func ParseRule(name string, raw []byte) (Rule, error) {
+	r := Rule{Name: name, Raw: raw, Extra: map[string]string{}}
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	front, body, ok := splitFrontmatter(text)
+	if !ok {
+		r.AlwaysApply = true
+		r.Body = strings.TrimLeft(text, "\n")
+		return r, nil
+	}
+	r.Body = strings.TrimLeft(body, "\n")
+	for i, line := range strings.Split(front, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return r, fmt.Errorf("rule %s: frontmatter line %d: expected key: value", name, i+2)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "description":
+			r.Description = unquote(value)
+		case "globs":
+			r.Globs = ParseGlobs(value)
+		case "alwaysApply":
+			b, err := strconv.ParseBool(value)
+			if value != "" && err != nil {
+				return r, fmt.Errorf("rule %s: alwaysApply: %w", name, err)
+			}
+			r.AlwaysApply = b
+		default:
+			r.Extra[key] = unquote(value)
+		}
+	}
+	return r, nil
+}
+
+// ParseGlobs splits a frontmatter globs value. Cursor writes comma-separated globs; a YAML flow
+// list such as ["*.go", "*.mod"] is accepted too. Commas inside braces, as in "*.{ts,tsx}", are
+// part of their glob.
+func ParseGlobs(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	var globs []string
+	add := func(g string) {
+		if g = unquote(strings.TrimSpace(g)); g != "" {
+			globs = append(globs, g)
+		}
+	}
+	depth, last := 0, 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				add(value[last:i])
+				last = i + 1
+			}
+		}
+	}
+	add(value[last:])
+	return globs
+}
+
+// JoinGlobs writes globs as one comma-separated value. Tools split such values at every comma, so
+// braces are expanded first: "**/*.{ts,tsx}" is written as "**/*.ts,**/*.tsx".
+func JoinGlobs(globs []string) string {
+	var out []string
+	for _, g := range globs {
+		out = append(out, expandBraces(g)...)
+	}
+	return strings.Join(out, ",")
+}
+
+// splitFrontmatter separates a leading "---" delimited block from the rest of the text.
+func splitFrontmatter(text string) (front, body string, ok bool) {
+	if !strings.HasPrefix(text, "---\n") {
+		return "", text, false
+	}
+	rest := text[len("---\n"):]
+	if strings.HasPrefix(rest, "---\n") {
+		return "", rest[len("---\n"):], true
+	}
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if strings.HasSuffix(rest, "\n---") {
+			return rest[:len(rest)-len("\n---")], "", true
+		}
+		return "", text, false
+	}
+	return rest[:end], rest[end+len("\n---\n"):], true
+}
+
+// FrontmatterValue writes s as a frontmatter value that ParseRule and YAML readers give back
+// unchanged: plain when it can be, single-quoted when it would otherwise start a comment, a quoted
+// string or a flow collection, or be cut at ": " or " #". Newlines become spaces, since values
+// are one line.
+func FrontmatterValue(s string) string {
+	s = strings.Join(strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), " ")
+	if s == "" || !needsQuotes(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func needsQuotes(s string) bool {
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s[:1], "#'\"[]{}&*!|>%@`") {
+		return true
+	}
+	if strings.HasPrefix(s, "- ") || strings.HasPrefix(s, "? ") || strings.HasPrefix(s, ": ") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.HasSuffix(s, ":") || strings.Contains(s, " #")
+}
+
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
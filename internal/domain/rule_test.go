@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRule_Frontmatter(t *testing.T) {
+	raw := []byte("---\ndescription: Go rules\nglobs: **/*.go, \"**/go.mod\"\nalwaysApply: false\nlanguage: go\n---\n\n**Go**\n")
+	r, err := ParseRule("go", raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Description != "Go rules" || r.AlwaysApply {
+		t.Errorf("unexpected fields: %+v", r)
+	}
+	if want := []string{"**/*.go", "**/go.mod"}; !reflect.DeepEqual(r.Globs, want) {
+		t.Errorf("globs: got %v, want %v", r.Globs, want)
+	}
+	if r.Extra["language"] != "go" {
+		t.Errorf("extra keys not kept: %v", r.Extra)
+	}
+	if r.Body != "**Go**\n" {
+		t.Errorf("unexpected body: %q", r.Body)
+	}
+	if r.Activation() != ActivationGlob {
+		t.Errorf("unexpected activation: %s", r.Activation())
+	}
+}
+
+func TestFrontmatterValue_RoundTrips(t *testing.T) {
+	for _, desc := range []string{
+		"Go rules",
+		"Setup: Docker",
+		"#1 rule",
+		"it's \"quoted\"",
+		"'single'",
+		"[draft] notes",
+		"C# rules # for .NET",
+		"ends with:",
+	} {
+		r := Rule{Name: "x", Description: desc, Body: "body\n"}
+		got, err := ParseRule("x", r.MDC())
+		if err != nil {
+			t.Errorf("%q: %v", desc, err)
+			continue
+		}
+		if got.Description != desc {
+			t.Errorf("description %q read back as %q from:\n%s", desc, got.Description, r.MDC())
+		}
+	}
+	if got := FrontmatterValue("Go rules"); got != "Go rules" {
+		t.Errorf("plain value should stay plain, got %s", got)
+	}
+}
+
+func TestParseGlobs_Braces(t *testing.T) {
+	globs := ParseGlobs(`**/*.{ts,tsx}, "src/{a,b}/*.go",*.md`)
+	if want := []string{"**/*.{ts,tsx}", "src/{a,b}/*.go", "*.md"}; !reflect.DeepEqual(globs, want) {
+		t.Fatalf("got %v, want %v", globs, want)
+	}
+	if !MatchGlob(globs[0], "a/b.tsx") {
+		t.Error("**/*.{ts,tsx} does not match a/b.tsx")
+	}
+	if got, want := JoinGlobs(globs), "**/*.ts,**/*.tsx,src/a/*.go,src/b/*.go,*.md"; got != want {
+		t.Errorf("JoinGlobs: got %q, want %q", got, want)
+	}
+	r := Rule{Globs: []string{"**/*.{ts,tsx}"}}
+	if got, _ := ParseRule("x", r.MDC()); !reflect.DeepEqual(got.Globs, []string{"**/*.ts", "**/*.tsx"}) {
+		t.Errorf("MDC globs do not round-trip: %v", got.Globs)
+	}
+}
+
+func TestParseRule_EmptyGlobsAndNoFrontmatter(t *testing.T) {
+	r, err := ParseRule("base", []byte("---\ndescription: Base\nglobs:\nalwaysApply: true\n---\nbody\n"))
+	if err != nil || len(r.Globs) != 0 || r.Activation() != ActivationAlways {
+		t.Errorf("unexpected result: %+v, %v", r, err)
+	}
+	r, err = ParseRule("plain", []byte("# Just markdown\n"))
+	if err != nil || !r.AlwaysApply || r.Body != "# Just markdown\n" {
+		t.Errorf("unexpected result for plain file: %+v, %v", r, err)
+	}
+}
+
+func TestParseRule_Activation(t *testing.T) {
+	cases := map[Activation]Rule{
+		ActivationModelDecision: {Description: "Use for SQL"},
+		ActivationManual:        {},
+	}
+	for want, r := range cases {
+		if got := r.Activation(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestParseRule_InvalidAlwaysApply(t *testing.T) {
+	if _, err := ParseRule("x", []byte("---\nalwaysApply: maybe\n---\n")); err == nil {
+		t.Error("expected error for invalid alwaysApply")
+	}
+}
+
+func TestScopeRule(t *testing.T) {
+	always, _ := ParseRule("go", []byte("---\ndescription: Go\nglobs:\nalwaysApply: true\n---\n\nbody\n"))
+	always.SourcePath = "/rules/gorules.mdc"
+	r := ScopeRule(always, "services/api")
+	if r.AlwaysApply || !reflect.DeepEqual(r.Globs, []string{"services/api/**"}) || r.SourcePath != "" {
+		t.Errorf("always rule not scoped: %+v", r)
+	}
+	want := "---\ndescription: Go\nglobs: services/api/**\nalwaysApply: false\n---\n\nbody\n"
+	if string(r.Raw) != want {
+		t.Errorf("raw:\n%s\nwant:\n%s", r.Raw, want)
+	}
+	reparsed, err := ParseRule("go", r.Raw)
+	if err != nil || !reflect.DeepEqual(reparsed.Globs, r.Globs) || reparsed.Body != r.Body {
+		t.Errorf("scoped rule does not round-trip: %+v, %v", reparsed, err)
+	}
+
+	glob := ScopeRule(Rule{Name: "ts", Globs: []string{"*.ts", "./src/**/*.tsx"}}, "web")
+	if want := []string{"web/**/*.ts", "web/src/**/*.tsx"}; !reflect.DeepEqual(glob.Globs, want) {
+		t.Errorf("globs: got %v, want %v", glob.Globs, want)
+	}
+
+	described := ScopeRule(Rule{Name: "review", Description: "Code review"}, "web")
+	if described.Description != "Code review (for web/)" || described.Activation() != ActivationModelDecision {
+		t.Errorf("model decision rule: %+v", described)
+	}
+}
+
+func TestSelectSections(t *testing.T) {
+	r, _ := ParseRule("go", []byte("---\ndescription: Go\nglobs:\nalwaysApply: true\n---\n\n## Concurrency\n\nChannels.\n\n## Testing\n\nTable tests.\n"))
+	name, sections := ParseRuleSpec("go#testing")
+	if name != "go" || !reflect.DeepEqual(sections, []string{"testing"}) {
+		t.Fatalf("ParseRuleSpec: %q %v", name, sections)
+	}
+	got, err := SelectSections(r, sections)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "---\ndescription: Go\nglobs:\nalwaysApply: true\nsections: testing\n---\n\n## Testing\n\nTable tests.\n"
+	if string(got.Raw) != want {
+		t.Errorf("raw:\n%q\nwant:\n%q", got.Raw, want)
+	}
+	if r.Extra[SectionsKey] != "" {
+		t.Error("the original rule was modified")
+	}
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Section is the part of a markdown document that starts at a heading and runs until the next
+// heading of the same or a higher level.
+type Section struct {
+	// Heading is the heading text without the leading #s.
+	Heading string
+	// Level is the number of #s, 1 to 6.
+	Level int
+	// Text is the whole section, including its heading line.
+	Text string
+}
+
+var headingPattern = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t]*#*[ \t]*$`)
+
+// Rules without markdown headings, like the shipped ones, are structured by bold labels instead: a
+// line such as "**Go-Specific Instructions:**", and numbered directives under it such as
+// "5.  **Concurrency:** ...".
+var (
+	labelPattern          = regexp.MustCompile(`^\*\*([^*]+?):?\*\*:?[ \t]*$`)
+	directiveLabelPattern = regexp.MustCompile(`^\d+\.[ \t]+\*\*([^*]+?):?\*\*`)
+)
+
+// SplitSections splits markdown into the text before the first heading of the given level and one
+// section per heading of that level. Deeper headings stay inside their section; headings inside
+// fenced code blocks are not headings.
+func SplitSections(markdown string, level int) (preamble string, sections []Section) {
+	var current *Section
+	var pre strings.Builder
+	forEachLine(markdown, func(line string, heading string, lvl int) {
+		if lvl > 0 && lvl <= level {
+			if current != nil {
+				sections = append(sections, *current)
+			}
+			current = &Section{Heading: heading, Level: lvl}
+		}
+		if current == nil {
+			pre.WriteString(line)
+			return
+		}
+		current.Text += line
+	})
+	if current != nil {
+		sections = append(sections, *current)
+	}
+	return pre.String(), sections
+}
+
+// SectionLevel returns the heading level a document is best split at: the highest level that
+// occurs more than once, skipping a single title heading. It returns 0 when there are no headings.
+func SectionLevel(markdown string) int {
+	var counts [7]int
+	forEachLine(markdown, func(_ string, _ string, lvl int) {
+		counts[lvl]++
+	})
+	top := 0
+	for lvl := 1; lvl <= 6; lvl++ {
+		if counts[lvl] == 0 {
+			continue
+		}
+		if top == 0 {
+			if counts[lvl] > 1 {
+				return lvl
+			}
+			top = lvl
+			continue
+		}
+		return lvl
+	}
+	return top
+}
+
+// forEachLine calls fn for every line of markdown, including its line ending, with the heading
+// text and level when the line is a heading outside a fenced code block.
+func forEachLine(markdown string, fn func(line, heading string, level int)) {
+	scanLines(markdown, false, fn)
+}
+
+// scanLines is forEachLine that, with labels set, also reports bold labels as level 1 headings and
+// labelled directives as level 2 headings.
+func scanLines(markdown string, labels bool, fn func(line, heading string, level int)) {
+	fence := ""
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			text := strings.TrimRight(line, "\r\n")
+			if m := headingPattern.FindStringSubmatch(text); m != nil {
+				fn(line, m[2], len(m[1]))
+				continue
+			}
+			if !labels {
+				break
+			}
+			if m := labelPattern.FindStringSubmatch(text); m != nil {
+				fn(line, m[1], 1)
+				continue
+			}
+			if m := directiveLabelPattern.FindStringSubmatch(text); m != nil {
+				fn(line, m[1], 2)
+				continue
+			}
+		}
+		fn(line, "", 0)
+	}
+}
+
+// Anchor returns the GitHub-style anchor of a heading: lower case, with punctuation dropped and
+// spaces turned into hyphens, e.g. "Error Handling & Logging" -> "error-handling--logging".
+func Anchor(heading string) string {
+	var sb strings.Builder
+	for _, c := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case c == ' ':
+			sb.WriteRune('-')
+		case c == '-' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// ExtractSections returns the sections of markdown whose heading anchors are listed, each with
+// the deeper headings and text nested under it, in document order. Anchors may also be given as
+// heading text. Markdown without headings is split at its bold labels and labelled directives, so
+// "concurrency" selects "5.  **Concurrency:** ...". It fails naming the anchors that match no heading.
+func ExtractSections(markdown string, anchors []string) (string, error) {
+	wanted := map[string]bool{}
+	for _, a := range anchors {
+		wanted[Anchor(a)] = false
+	}
+	var out strings.Builder
+	var available []string
+	capturing := 0 // level of the section being copied, 0 when outside one
+	scanLines(markdown, SectionLevel(markdown) == 0, func(line string, heading string, lvl int) {
+		if lvl > 0 {
+			available = append(available, Anchor(heading))
+			if capturing > 0 && lvl <= capturing {
+				capturing = 0
+			}
+			if _, ok := wanted[Anchor(heading)]; ok && capturing == 0 {
+				if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n\n") {
+					out.WriteString("\n")
+				}
+				wanted[Anchor(heading)] = true
+				capturing = lvl
+			}
+		}
+		if capturing > 0 {
+			out.WriteString(line)
+		}
+	})
+	var missing []string
+	for _, a := range anchors {
+		if !wanted[Anchor(a)] {
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) > 0 && len(available) == 0 {
+		return "", fmt.Errorf("no section %s: there are no headings or **Label:** lines to select", strings.Join(missing, ", "))
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no section %s (sections: %s)", strings.Join(missing, ", "), strings.Join(available, ", "))
+	}
+	return strings.TrimRight(out.String(), "\n") + "\n", nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sectionDoc = "# Project\n\nIntro.\n\n## Testing\n\nRun tests.\n\n```sh\n# not a heading\n```\n\n### Details\n\nMore.\n\n## Style\n\nBe terse.\n"
+
+func TestSectionLevel(t *testing.T) {
+	cases := map[string]int{
+		sectionDoc:                  2,
+		"# A\n\nx\n\n# B\n\ny\n":    1,
+		"# Only\n\ntext\n":          1,
+		"no headings\n":             0,
+		"# T\n\n## A\n\n### B\n":    2,
+		"```\n# a\n# b\n```\n# c\n": 1,
+	}
+	for doc, want := range cases {
+		if got := SectionLevel(doc); got != want {
+			t.Errorf("SectionLevel(%q) = %d, want %d", doc, got, want)
+		}
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	pre, sections := SplitSections(sectionDoc, 2)
+	if pre != "" {
+		t.Errorf("unexpected preamble: %q", pre)
+	}
+	if len(sections) != 3 {
+		t.Fatalf("expected 3 sections, got %+v", sections)
+	}
+	if sections[0].Heading != "Project" || sections[0].Level != 1 || sections[0].Text != "# Project\n\nIntro.\n\n" {
+		t.Errorf("unexpected title section: %+v", sections[0])
+	}
+	want := "## Testing\n\nRun tests.\n\n```sh\n# not a heading\n```\n\n### Details\n\nMore.\n\n"
+	if sections[1].Heading != "Testing" || sections[1].Text != want {
+		t.Errorf("unexpected section: %q", sections[1].Text)
+	}
+	if sections[2].Heading != "Style" || sections[2].Text != "## Style\n\nBe terse.\n" {
+		t.Errorf("unexpected last section: %+v", sections[2])
+	}
+
+	pre, sections = SplitSections("Intro.\n\n# A\n", 1)
+	if pre != "Intro.\n\n" || len(sections) != 1 || sections[0].Heading != "A" {
+		t.Errorf("unexpected split: %q, %+v", pre, sections)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	cases := map[string]string{
+		"Concurrency":              "concurrency",
+		"Error Handling & Logging": "error-handling--logging",
+		"  Testing (pytest)  ":     "testing-pytest",
+		"snake_case names":         "snake_case-names",
+	}
+	for heading, want := range cases {
+		if got := Anchor(heading); got != want {
+			t.Errorf("Anchor(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
+
+func TestExtractSections(t *testing.T) {
+	md := "# Go\n\nIntro.\n\n## Concurrency\n\nUse channels.\n\n### Mutexes\n\nKeep them small.\n\n## Testing\n\n```go\n## not a heading\n```\n\n## Style\n\ngofmt.\n"
+	got, err := ExtractSections(md, []string{"Testing", "concurrency"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "## Concurrency\n\nUse channels.\n\n### Mutexes\n\nKeep them small.\n\n## Testing\n\n```go\n## not a heading\n```\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := ExtractSections(md, []string{"generics"}); err == nil || !strings.Contains(err.Error(), "concurrency") {
+		t.Errorf("expected an error listing the sections, got %v", err)
+	}
+}
+
+func TestExtractSections_Labels(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("..", "..", "rules", "gorules.mdc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ParseRule("go", raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ExtractSections(r.Body, []string{"concurrency", "Error Handling"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(got, "2.  **Error Handling:**") || !strings.Contains(got, "\n5.  **Concurrency:**") || strings.Contains(got, "Formatting") {
+		t.Errorf("unexpected sections:\n%s", got)
+	}
+	got, err = ExtractSections(r.Body, []string{"go-specific-instructions"})
+	if err != nil || !strings.Contains(got, "1.  **Standard Library:**") || !strings.Contains(got, "5.  **Concurrency:**") {
+		t.Errorf("a label should include its directives: %q, %v", got, err)
+	}
+	if _, err := ExtractSections(r.Body, []string{"testing"}); err == nil || !strings.Contains(err.Error(), "(sections: go-specific-instructions, standard-library,") {
+		t.Errorf("expected an error listing the labels, got %v", err)
+	}
+
+	// Labels are not sections when the rule has real headings.
+	if _, err := ExtractSections("## Go\n\n1.  **Concurrency:** Use channels.\n", []string{"concurrency"}); err == nil {
+		t.Error("a directive label was taken as a section next to headings")
+	}
+	if _, err := ExtractSections("Just text.\n", []string{"testing"}); err == nil || !strings.Contains(err.Error(), "no headings") {
+		t.Errorf("expected an error saying there are no headings, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+// Target is an AI tool that rules can be installed into. Each target knows where the tool reads
+// rules from and how to translate .mdc rules into its format.
+type Target interface {
+	// Name is the identifier used with --target, e.g. "cursor".
+	Name() string
+	// Layout describes where and how the tool stores rules.
+	Layout() TargetLayout
+	// Render converts rules into the files the tool reads. Paths are relative to the install base dir.
+	Render(rules []Rule, opts RenderOptions) ([]TargetFile, error)
+}
+
+// TargetLayout describes a target's on-disk format.
+type TargetLayout struct {
+	// Dir is the directory rule files are written to, relative to the install base dir.
+	Dir string
+	// SingleFile is true when the tool reads all rules from one file rather than one file per rule.
+	SingleFile bool
+	// Frontmatter lists the frontmatter keys the tool understands; empty means none.
+	Frontmatter []string
+}
+
+// RenderOptions adjusts how a target renders rules.
+type RenderOptions struct {
+	// Consolidate merges all rules into one file for targets that otherwise write one file per rule.
+	Consolidate bool
+	// BaseDir is the install base dir, for targets whose output depends on the tool's own settings.
+	BaseDir string
+}
+
+// TargetFile is one file produced by a target.
+type TargetFile struct {
+	// Path is relative to the install base dir.
+	Path string
+	// Content is the complete file content, or the managed block content when Managed is set.
+	Content []byte
+	// Rules lists the rules the file was rendered from.
+	Rules []string
+	// LinkSource is set when Content is byte-for-byte a rule file on disk, so link modes can point at it.
+	LinkSource string
+	// Managed means Content is spliced into a managed block of Path, preserving the rest of the file.
+	Managed bool
+	// Warning is shown when the file is installed, e.g. because it exceeds the tool's size limit.
+	Warning string
+}
+
+// GlobalTarget is implemented by targets whose tool reads user-level rules from a known place in
+// the home directory, which often differs from the project layout.
+type GlobalTarget interface {
+	Target
+	// Global returns the target as installed for the user, with paths relative to the home dir.
+	Global() Target
+}
+
+// ConfiguredTarget is a Target whose tool only reads the rendered files once its config file points
+// at them. Configure runs after every install and Unconfigure once the target's files are removed;
+// both report whether they changed anything.
+type ConfiguredTarget interface {
+	Target
+	Configure(baseDir string) (bool, error)
+	Unconfigure(baseDir string) (bool, error)
+}
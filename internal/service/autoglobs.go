@@ -0,0 +1,44 @@
+package service
+
+import (
+	"strings"
+
+	"ai-rules-link/internal/detect"
+	"ai-rules-link/internal/domain"
+)
+
+// LanguageKey is the rule frontmatter key naming the language a rule is about, e.g. "language: go".
+const LanguageKey = "language"
+
+// AutoGlobTarget installs always-applied language rules so they are attached only to files of their
+// language, e.g. gorules with "**/*.go". The language comes from the rule's language frontmatter,
+// or else from its name when that is a technology ai-rules-link detects. Other rules are unchanged.
+type AutoGlobTarget struct {
+	domain.Target
+}
+
+// Render implements domain.Target.
+func (t AutoGlobTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	return t.Target.Render(autoGlobRules(rules), opts)
+}
+
+// autoGlobRules limits the always-applied rules with a known language to the files of that language.
+func autoGlobRules(rules []domain.Rule) []domain.Rule {
+	scoped := make([]domain.Rule, len(rules))
+	for i, r := range rules {
+		scoped[i] = r
+		if globs := LanguageGlobs(r); globs != nil && r.Activation() == domain.ActivationAlways {
+			scoped[i] = domain.LimitToGlobs(r, globs)
+		}
+	}
+	return scoped
+}
+
+// LanguageGlobs returns the globs an always-applied rule is limited to by AutoGlobTarget, or nil
+// when its language is unknown.
+func LanguageGlobs(r domain.Rule) []string {
+	if language := strings.TrimSpace(r.Extra[LanguageKey]); language != "" {
+		return detect.Globs(language)
+	}
+	return detect.Globs(r.Name)
+}
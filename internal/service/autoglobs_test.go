@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestInstallRules_AutoGlobs(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc":    "---\ndescription: Base\nglobs:\nalwaysApply: true\n---\nBase\n",
+		"gorules.mdc":      "---\ndescription: Go\nglobs:\nalwaysApply: true\n---\nGo\n",
+		"backendrules.mdc": "---\ndescription: Backend\nglobs:\nalwaysApply: true\nlanguage: python\n---\nPy\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base", "go", "backend"}, Mode: domain.ModeSymlink, Source: DirSource("test", canon),
+		BaseDir: project, AutoGlobs: true, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	rulesDir := filepath.Join(project, ".cursor", "rules")
+	if _, err := os.Readlink(filepath.Join(rulesDir, "baserules.mdc")); err != nil {
+		t.Errorf("base has no language and should stay a symlink: %v", err)
+	}
+	goRule, err := os.ReadFile(filepath.Join(rulesDir, "gorules.mdc"))
+	if err != nil || !strings.Contains(string(goRule), "globs: **/*.go,**/go.mod\nalwaysApply: false\n") {
+		t.Errorf("go rule not limited to Go files: %q, %v", goRule, err)
+	}
+	if info, err := os.Lstat(filepath.Join(rulesDir, "gorules.mdc")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("rewritten rule should be a copy: %v", err)
+	}
+	backend, _ := os.ReadFile(filepath.Join(rulesDir, "backendrules.mdc"))
+	if !strings.Contains(string(backend), "globs: **/*.py,") {
+		t.Errorf("language frontmatter not used: %q", backend)
+	}
+
+	drift, _, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest})
+	if err != nil || len(drift) != 0 {
+		t.Errorf("fresh auto-glob install reported as drifted: %+v, %v", drift, err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// VersionMarkerFilename is written into a bootstrapped rules directory to record what was exported.
+const VersionMarkerFilename = ".ai-rules-version"
+
+// VersionMarker records the binary version that last exported the embedded rules, and the hash of
+// each rule as exported, so later updates can tell user edits from stale copies.
+type VersionMarker struct {
+	Version string            `json:"version"`
+	Rules   map[string]string `json:"rules"`
+}
+
+// BootstrapOptions configures BootstrapRules.
+type BootstrapOptions struct {
+	Embedded fs.FS
+	Dest     string
+	Version  string
+	Update   bool // bring an already bootstrapped directory up to date
+	Stdout   io.Writer
+}
+
+// BootstrapRules exports the embedded rule set into Dest. Existing rules are never overwritten on the
+// first run. With Update, rules added in newer binaries are written and rules the user has not edited
+// since the last export are upgraded; edited rules are left alone.
+func BootstrapRules(ctx context.Context, opts BootstrapOptions) error {
+	marker, err := readVersionMarker(opts.Dest)
+	if err != nil {
+		return err
+	}
+	if marker != nil && !opts.Update {
+		return fmt.Errorf("%s was already bootstrapped by version %s; use --update to bring in newer rules", opts.Dest, marker.Version)
+	}
+	if marker == nil {
+		marker = &VersionMarker{}
+	}
+	if marker.Rules == nil {
+		marker.Rules = map[string]string{}
+	}
+
+	src := EmbeddedSource(opts.Embedded)
+	rules, err := src.ListRules()
+	if err != nil {
+		return fmt.Errorf("list embedded rules: %w", err)
+	}
+	if err := os.MkdirAll(opts.Dest, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", opts.Dest, err)
+	}
+	for _, rule := range rules {
+		filename := RuleFilename(rule)
+		content, err := src.ReadRule(rule)
+		if err != nil {
+			return fmt.Errorf("read embedded %s: %w", filename, err)
+		}
+		embeddedHash := ContentHash(content)
+		dst := filepath.Join(opts.Dest, filename)
+		current, err := os.ReadFile(dst)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.WriteFile(dst, content, 0644); err != nil {
+				return fmt.Errorf("write %s: %w", dst, err)
+			}
+			fmt.Fprintf(opts.Stdout, "Added %s\n", filename)
+		case err != nil:
+			return fmt.Errorf("read %s: %w", dst, err)
+		case ContentHash(current) == embeddedHash:
+			// Already up to date.
+		case marker.Rules[filename] != "" && ContentHash(current) == marker.Rules[filename]:
+			if err := os.WriteFile(dst, content, 0644); err != nil {
+				return fmt.Errorf("write %s: %w", dst, err)
+			}
+			fmt.Fprintf(opts.Stdout, "Updated %s\n", filename)
+		default:
+			fmt.Fprintf(opts.Stdout, "Kept %s: it has local changes\n", filename)
+			continue
+		}
+		marker.Rules[filename] = embeddedHash
+	}
+	marker.Version = opts.Version
+	return writeVersionMarker(opts.Dest, marker)
+}
+
+// readVersionMarker returns the marker in dir, or nil if the directory was never bootstrapped.
+func readVersionMarker(dir string) (*VersionMarker, error) {
+	data, err := os.ReadFile(filepath.Join(dir, VersionMarkerFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read version marker: %w", err)
+	}
+	var marker VersionMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("parse version marker: %w", err)
+	}
+	return &marker, nil
+}
+
+func writeVersionMarker(dir string, marker *VersionMarker) error {
+	data, err := json.MarshalIndent(marker, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode version marker: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, VersionMarkerFilename), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write version marker: %w", err)
+	}
+	return nil
+}
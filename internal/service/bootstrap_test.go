@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBootstrapRules_ExportsWithoutClobbering(t *testing.T) {
+	embedded := fstest.MapFS{
+		"rules/gorules.mdc":   {Data: []byte("go v1")},
+		"rules/baserules.mdc": {Data: []byte("base v1")},
+	}
+	dest := newCanonicalDir(t, map[string]string{"gorules.mdc": "my go"})
+	err := BootstrapRules(context.Background(), BootstrapOptions{Embedded: embedded, Dest: dest, Version: "1", Stdout: io.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "gorules.mdc")); string(got) != "my go" {
+		t.Errorf("customized rule was overwritten: %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "baserules.mdc")); string(got) != "base v1" {
+		t.Errorf("missing rule was not exported: %q", got)
+	}
+	marker, err := readVersionMarker(dest)
+	if err != nil || marker == nil || marker.Version != "1" {
+		t.Fatalf("unexpected marker: %+v, %v", marker, err)
+	}
+	if _, ok := marker.Rules["gorules.mdc"]; ok {
+		t.Errorf("customized rule should not be recorded as exported")
+	}
+
+	err = BootstrapRules(context.Background(), BootstrapOptions{Embedded: embedded, Dest: dest, Version: "1", Stdout: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "--update") {
+		t.Errorf("expected a second bootstrap to ask for --update, got: %v", err)
+	}
+}
+
+func TestBootstrapRules_Update(t *testing.T) {
+	dest := t.TempDir()
+	v1 := fstest.MapFS{
+		"rules/gorules.mdc":     {Data: []byte("go v1")},
+		"rules/pythonrules.mdc": {Data: []byte("python v1")},
+	}
+	if err := BootstrapRules(context.Background(), BootstrapOptions{Embedded: v1, Dest: dest, Version: "1", Stdout: io.Discard}); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	os.WriteFile(filepath.Join(dest, "pythonrules.mdc"), []byte("my python"), 0644)
+
+	v2 := fstest.MapFS{
+		"rules/gorules.mdc":     {Data: []byte("go v2")},
+		"rules/pythonrules.mdc": {Data: []byte("python v2")},
+		"rules/rustrules.mdc":   {Data: []byte("rust v2")},
+	}
+	if err := BootstrapRules(context.Background(), BootstrapOptions{Embedded: v2, Dest: dest, Version: "2", Update: true, Stdout: io.Discard}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	for file, want := range map[string]string{"gorules.mdc": "go v2", "pythonrules.mdc": "my python", "rustrules.mdc": "rust v2"} {
+		if got, _ := os.ReadFile(filepath.Join(dest, file)); string(got) != want {
+			t.Errorf("%s: got %q, want %q", file, got, want)
+		}
+	}
+	if marker, _ := readVersionMarker(dest); marker.Version != "2" {
+		t.Errorf("marker version not updated: %+v", marker)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+
+	"ai-rules-link/internal/utils"
+)
+
+// Drift is an installed file whose content no longer matches what its target renders from the
+// current rule sources.
+type Drift struct {
+	Target string
+	// Path is the installed file, relative to the manifest directory.
+	Path string
+	// Problem explains drift that has no diff, e.g. a missing file or a rule that can no longer be read.
+	Problem string
+	// Diff is a unified diff from the content on disk to the rendered content.
+	Diff string
+	// Unavailable means the file's rule source is not on this machine, so it could not be checked.
+	// It is not drift.
+	Unavailable bool
+}
+
+// CheckOptions configures CheckTargets.
+type CheckOptions struct {
+	Manifest *Manifest
+	Embedded fs.FS // used for entries installed from the embedded rules
+}
+
+// CheckTargets re-renders every file recorded in the manifest, in memory, and compares it with the
+// file on disk. For managed files only the managed block is compared. Nothing is written. It returns
+// the drifted files, including those whose rule source is unavailable, and the number of files checked.
+func CheckTargets(ctx context.Context, opts CheckOptions) ([]Drift, int, error) {
+	var drift []Drift
+	checked := 0
+	for _, e := range opts.Manifest.Entries {
+		if err := ctx.Err(); err != nil {
+			return drift, checked, err
+		}
+		checked++
+		d := Drift{Target: entryTarget(e), Path: e.Path}
+		src := opts.Manifest.entrySource(e, opts.Embedded)
+		if !src.Embedded() && !isDir(src.Dir) {
+			d.Problem = fmt.Sprintf("rule source %s is unavailable (%s)", e.Source, src.Dir)
+			d.Unavailable = true
+			drift = append(drift, d)
+			continue
+		}
+		f, err := renderEntry(opts.Manifest, e, src)
+		if err != nil {
+			d.Problem = err.Error()
+			drift = append(drift, d)
+			continue
+		}
+		got, err := installedContent(opts.Manifest.Abs(e), e.Managed)
+		switch {
+		case err != nil && e.Managed:
+			d.Problem = "managed block is missing"
+		case err != nil:
+			d.Problem = fmt.Sprintf("file is missing or unreadable: %v", err)
+		case !bytes.Equal(got, f.Content):
+			d.Diff = utils.UnifiedDiff(e.Path+" (on disk)", e.Path+" (rendered)", got, f.Content)
+		default:
+			continue
+		}
+		drift = append(drift, d)
+	}
+	return drift, checked, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestCheckTargets_ReportsDriftPerTarget(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc": "---\nalwaysApply: true\n---\nBase v1\n",
+		"gorules.mdc":   "---\nglobs: *.go\nalwaysApply: false\n---\nGo v1\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	for _, name := range []string{"cursor", "claude", "copilot"} {
+		target, _ := LookupTarget(name)
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: []string{"base", "go"}, Mode: domain.ModeCopy, Source: DirSource("test", canon),
+			Target: target, BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install %s: %v", name, err)
+		}
+	}
+	drift, checked, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest})
+	if err != nil || len(drift) != 0 {
+		t.Fatalf("expected no drift right after install, got %+v, %v", drift, err)
+	}
+
+	os.WriteFile(filepath.Join(canon, "gorules.mdc"), []byte("---\nglobs: *.go\nalwaysApply: false\n---\nGo v2\n"), 0644)
+	os.Remove(filepath.Join(project, ".github", "copilot-instructions.md"))
+	drift, _, err = CheckTargets(context.Background(), CheckOptions{Manifest: manifest})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]Drift{}
+	for _, d := range drift {
+		got[d.Path] = d
+	}
+	for _, path := range []string{".cursor/rules/gorules.mdc", ".claude/rules/go.md", ".github/instructions/go.instructions.md"} {
+		if d, ok := got[path]; !ok || !strings.Contains(d.Diff, "-Go v1") || !strings.Contains(d.Diff, "+Go v2") {
+			t.Errorf("expected a diff for %s, got %+v", path, d)
+		}
+	}
+	if d := got[".github/copilot-instructions.md"]; d.Target != "copilot" || d.Problem == "" {
+		t.Errorf("expected the missing copilot file to be reported, got %+v", d)
+	}
+	if len(drift) != 4 || checked == 0 {
+		t.Errorf("expected 4 drifted files, got %d", len(drift))
+	}
+}
+
+func TestCheckTargets_AfterTheProjectMoves(t *testing.T) {
+	const base = "---\nalwaysApply: true\n---\nBase\n"
+	const goRule = "---\nalwaysApply: true\n---\nGo\n"
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, "ai-rules"), 0755)
+	os.WriteFile(filepath.Join(home, "ai-rules", "gorules.mdc"), []byte(goRule), 0644)
+	project := filepath.Join(t.TempDir(), "proj")
+	os.MkdirAll(filepath.Join(project, ProjectRulesDir), 0755)
+	os.WriteFile(filepath.Join(project, ProjectRulesDir, "baserules.mdc"), []byte(base), 0644)
+
+	manifest, _ := LoadManifest(project)
+	for _, src := range []RuleSource{DirSource(ProjectRulesDir, filepath.Join(project, ProjectRulesDir)), DirSource("~/ai-rules", filepath.Join(home, "ai-rules"))} {
+		rules, _ := src.ListRules()
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: rules, Mode: domain.ModeCopy, Source: src, BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install from %s: %v", src.Name, err)
+		}
+	}
+	if err := manifest.Save(); err != nil {
+		t.Fatal(err)
+	}
+	sources := map[string]string{}
+	for _, e := range manifest.Entries {
+		sources[e.Rules[0]] = e.Source
+	}
+	if sources["base"] != ProjectRulesDir || sources["go"] != "~/ai-rules" {
+		t.Errorf("sources recorded as %v, want .ai-rules and ~/ai-rules", sources)
+	}
+
+	// Another checkout on another machine: the project moves and the home dir has no rules.
+	moved := filepath.Join(t.TempDir(), "checkout")
+	if err := os.Rename(project, moved); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	manifest, _ = LoadManifest(moved)
+	drift, checked, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest})
+	if err != nil || checked != 2 {
+		t.Fatalf("unexpected result: %d checked, %v", checked, err)
+	}
+	if len(drift) != 1 || !drift[0].Unavailable || drift[0].Path != ".cursor/rules/gorules.mdc" {
+		t.Errorf("expected only the go rule to be unavailable, got %+v", drift)
+	}
+
+	// Once the user rules are there again, nothing has drifted.
+	t.Setenv("HOME", home)
+	if drift, _, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest}); err != nil || len(drift) != 0 {
+		t.Errorf("expected no drift, got %+v, %v", drift, err)
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"ai-rules-link/internal/utils"
 )
 
-// ContextService implements domain.ContextInitializer and domain.PromptGenerator.
+// ContextService implements domain.PromptGenerator.
 type ContextService struct {
 	RulesFS fs.FS
 }
@@ -71,59 +71,6 @@ func (s *ContextService) GeneratePromptFlexible(ctx context.Context, language st
 	return append(basePrompt, techPrompt...), nil
 }
 
-// Initialize sets up the context for the given tool and technology.
-func (s *ContextService) Initialize(ctx context.Context, tool, technology string) error {
-	prompt, err := s.GeneratePrompt(ctx, technology)
-	if err != nil {
-		return err
-	}
-
-	projectContextDir := ".context"
-	if err := os.MkdirAll(projectContextDir, 0755); err != nil {
-		return fmt.Errorf("create context dir: %w", err)
-	}
-
-	if err := utils.EnsureGitignore(); err != nil {
-		return fmt.Errorf("ensure .gitignore: %w", err)
-	}
-
-	projectPromptFile := filepath.Join(projectContextDir, "prompt.mdc")
-	if err := utils.WriteBytes(projectPromptFile, prompt); err != nil {
-		return fmt.Errorf("write prompt file: %w", err)
-	}
-
-	var symlinkPath string
-	switch tool {
-	case "gemini":
-		symlinkPath = filepath.Join(".gemini", "context.mdc")
-	case "cursor":
-		symlinkPath = filepath.Join(".vscode", "cursor_prompt.mdc")
-	default:
-		return nil // No symlink for unsupported tools
-	}
-
-	if err := os.MkdirAll(filepath.Dir(symlinkPath), 0755); err != nil {
-		return fmt.Errorf("create symlink dir: %w", err)
-	}
-
-	relPromptPath, err := filepath.Rel(filepath.Dir(symlinkPath), projectPromptFile)
-	if err != nil {
-		return fmt.Errorf("rel prompt path: %w", err)
-	}
-
-	if _, err := os.Lstat(symlinkPath); err == nil {
-		if err := os.Remove(symlinkPath); err != nil {
-			return fmt.Errorf("remove existing symlink: %w", err)
-		}
-	}
-
-	if err := os.Symlink(relPromptPath, symlinkPath); err != nil {
-		return fmt.Errorf("create symlink: %w", err)
-	}
-
-	return nil
-}
-
 // InitializeFlexible sets up the context for the given language, with options for baseOnly or langOnly.
 func (s *ContextService) InitializeFlexible(ctx context.Context, language string, baseOnly, langOnly bool) error {
 	prompt, err := s.GeneratePromptFlexible(ctx, language, baseOnly, langOnly)
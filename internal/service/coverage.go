@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// CoverageOptions configures ProjectCoverage.
+type CoverageOptions struct {
+	Manifest *Manifest
+	Embedded fs.FS // used for entries installed from the embedded rules
+}
+
+// CoverageCount is a number of files for a rule, a file type or a directory.
+type CoverageCount struct {
+	Name  string
+	Files int
+}
+
+// CoverageReport classifies a project's files by the installed rules that apply to them.
+type CoverageReport struct {
+	// Files is the number of files checked.
+	Files int
+	// Always lists the rules attached to every file, which say nothing about coverage.
+	Always []string
+	// Rules counts the files each scoped rule applies to: rules attached by glob or read only for a
+	// package's files. Most files first.
+	Rules []CoverageCount
+	// Uncovered is the number of files no scoped rule applies to.
+	Uncovered int
+	// UncoveredTypes counts uncovered files by extension, or by file name when there is none.
+	UncoveredTypes []CoverageCount
+	// UncoveredDirs counts uncovered files by top-level directory; "." holds files in the root.
+	UncoveredDirs []CoverageCount
+}
+
+// ProjectCoverage walks the project, skipping hidden directories and whatever .gitignore files
+// exclude, and evaluates every installed rule for every file with the same matcher as ExplainFile.
+func ProjectCoverage(ctx context.Context, opts CoverageOptions) (CoverageReport, error) {
+	var report CoverageReport
+	installed := loadInstalledRules(opts.Manifest, opts.Embedded)
+	always := map[string]bool{}
+	rules := map[string]int{}
+	types := map[string]int{}
+	dirs := map[string]int{}
+	root := opts.Manifest.Dir()
+	var ignores []ignorePattern
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || isIgnored(ignores, rel, true)) {
+				return filepath.SkipDir
+			}
+			ignores = append(ignores, loadGitignore(p, rel)...)
+			return nil
+		}
+		if !d.Type().IsRegular() || isIgnored(ignores, rel, false) {
+			return nil
+		}
+		report.Files++
+		covered := false
+		for _, ir := range installed {
+			if ir.Verdict != "" {
+				continue
+			}
+			if verdict, _ := ir.evaluate(rel); verdict != VerdictApplies {
+				continue
+			}
+			if ir.pkg == "" && (ir.noMetadata || ir.rule.Activation() == domain.ActivationAlways) {
+				always[ir.Rule] = true
+				continue
+			}
+			rules[ir.Rule]++
+			covered = true
+		}
+		if !covered {
+			report.Uncovered++
+			types[fileType(rel)]++
+			top, _, found := strings.Cut(rel, "/")
+			if !found {
+				top = "."
+			}
+			dirs[top]++
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	for r := range always {
+		report.Always = append(report.Always, r)
+	}
+	sort.Strings(report.Always)
+	report.Rules = sortedCounts(rules)
+	report.UncoveredTypes = sortedCounts(types)
+	report.UncoveredDirs = sortedCounts(dirs)
+	return report, nil
+}
+
+// fileType returns a file's extension, or its name when it has none, e.g. ".ts" or "Dockerfile".
+func fileType(rel string) string {
+	if ext := path.Ext(rel); ext != "" && ext != path.Base(rel) {
+		return ext
+	}
+	return path.Base(rel)
+}
+
+func sortedCounts(counts map[string]int) []CoverageCount {
+	out := make([]CoverageCount, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, CoverageCount{Name: name, Files: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Files != out[j].Files {
+			return out[i].Files > out[j].Files
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// ignorePattern is one line of a .gitignore file.
+type ignorePattern struct {
+	base    string // directory of the .gitignore, relative to the project; "." for the root
+	glob    domain.Glob
+	negate  bool
+	dirOnly bool
+}
+
+// loadGitignore reads the .gitignore in dir, if any. Patterns without a slash match at any depth
+// below dir; patterns with one are anchored to dir.
+func loadGitignore(dir, rel string) []ignorePattern {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ignorePattern{base: rel}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.glob = domain.CompilePathGlob(line)
+		} else {
+			p.glob = domain.CompileGlob(line)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// isIgnored applies patterns in order, so a later negated pattern can include a path again.
+func isIgnored(patterns []ignorePattern, rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		name := rel
+		if p.base != "." {
+			if !underDir(rel, p.base) {
+				continue
+			}
+			name = strings.TrimPrefix(rel, p.base+"/")
+		}
+		if p.glob.Match(name) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
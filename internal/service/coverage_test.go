@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestProjectCoverage(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc": "---\ndescription: Base\nglobs:\nalwaysApply: true\n---\nBase\n",
+		"gorules.mdc":   "---\ndescription: Go\nglobs: **/*.go\nalwaysApply: false\n---\nGo\n",
+	})
+	project := t.TempDir()
+	write := func(rel string) {
+		p := filepath.Join(project, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte("x"), 0644)
+	}
+	for _, f := range []string{"main.go", "cmd/root.go", "web/a.ts", "web/b.ts", "web/c.tsx", "Dockerfile",
+		"dist/bundle.ts", "web/gen/x.ts", "web/gen/keep.ts", ".git/config", "node_modules/y/index.ts"} {
+		write(f)
+	}
+	os.WriteFile(filepath.Join(project, ".gitignore"), []byte("# build output\ndist/\nnode_modules\n"), 0644)
+	os.WriteFile(filepath.Join(project, "web", ".gitignore"), []byte("gen/*\n!gen/keep.ts\n"), 0644)
+
+	manifest, _ := LoadManifest(project)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base", "go"}, Mode: domain.ModeCopy, Source: DirSource("test", canon),
+		BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	report, err := ProjectCoverage(context.Background(), CoverageOptions{Manifest: manifest})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// main.go, cmd/root.go, web/a.ts, web/b.ts, web/c.tsx, web/gen/keep.ts, Dockerfile and the two
+	// .gitignore files; .cursor/ is hidden.
+	if report.Files != 9 {
+		t.Errorf("expected 9 files, got %d", report.Files)
+	}
+	if !reflect.DeepEqual(report.Always, []string{"base"}) {
+		t.Errorf("unexpected always rules: %v", report.Always)
+	}
+	if !reflect.DeepEqual(report.Rules, []CoverageCount{{"go", 2}}) {
+		t.Errorf("unexpected rule coverage: %v", report.Rules)
+	}
+	if report.Uncovered != 7 || report.UncoveredTypes[0] != (CoverageCount{".ts", 3}) || report.UncoveredDirs[0] != (CoverageCount{"web", 5}) {
+		t.Errorf("unexpected gaps: %+v", report)
+	}
+}
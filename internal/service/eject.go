@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// EjectOptions configures EjectRules.
+type EjectOptions struct {
+	Rules    []string  // limit to these rules; empty ejects every recorded link
+	Manifest *Manifest // the links to eject are read from here and their copies recorded; the caller saves it
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// EjectRules replaces the symlinks recorded in the manifest, for every target and package, with
+// real copies of their targets, so the rules can be committed to the project. Symlinks the manifest
+// does not record are the user's and are left alone.
+func EjectRules(ctx context.Context, opts EjectOptions) error {
+	ejected := 0
+	for _, e := range append([]ManifestEntry{}, opts.Manifest.Entries...) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if e.Mode != domain.ModeSymlink && e.Mode != domain.ModeRelSymlink || len(opts.Rules) > 0 && !allSelected(opts.Rules, e.Rules) {
+			continue
+		}
+		dst := opts.Manifest.Abs(e)
+		if info, err := os.Lstat(dst); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			fmt.Fprintf(opts.Stderr, "Skipping %s: no longer a symlink\n", e.Path)
+			continue
+		}
+		target, err := os.Readlink(dst)
+		if err != nil {
+			fmt.Fprintf(opts.Stderr, "Could not read symlink %s: %v\n", dst, err)
+			continue
+		}
+		content, err := os.ReadFile(dst)
+		if err != nil {
+			fmt.Fprintf(opts.Stderr, "Skipping %s: broken symlink to %s\n", e.Path, target)
+			continue
+		}
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("remove symlink %s: %w", dst, err)
+		}
+		if err := os.WriteFile(dst, content, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", dst, err)
+		}
+		e.Mode = domain.ModeCopy
+		e.SHA256 = ContentHash(content)
+		opts.Manifest.Record(e)
+		fmt.Fprintf(opts.Stdout, "Ejected %s (was -> %s)\n", e.Path, target)
+		ejected++
+	}
+	if ejected == 0 {
+		fmt.Fprintf(opts.Stdout, "No rule symlinks recorded in %s to eject\n", ManifestFilename)
+	}
+	return nil
+}
+
+// AdoptOptions configures AdoptRules.
+type AdoptOptions struct {
+	Rules         []string // limit to these rules; empty adopts every matching copy
+	Source        RuleSource
+	DestRulesPath string
+	Relative      bool // create relative instead of absolute symlinks
+	Force         bool // adopt copies even when their content differs from the canonical rule
+	Manifest      *Manifest
+	Stdout        io.Writer
+	Stderr        io.Writer
+}
+
+// AdoptRules turns plain rule copies in DestRulesPath back into symlinks to the canonical source.
+// Copies whose content differs from the source are reported with a diff and left alone unless Force is set.
+func AdoptRules(ctx context.Context, opts AdoptOptions) error {
+	if opts.Source.Embedded() {
+		return fmt.Errorf("adopt needs a rules directory to link to; create ~/ai-rules or set XDG_CONFIG_HOME")
+	}
+	entries, err := os.ReadDir(opts.DestRulesPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", opts.DestRulesPath, err)
+	}
+	mode := domain.ModeSymlink
+	if opts.Relative {
+		mode = domain.ModeRelSymlink
+	}
+	for _, entry := range entries {
+		rule, ok := RuleName(entry.Name())
+		if !ok || !entry.Type().IsRegular() || !selected(opts.Rules, rule) {
+			continue
+		}
+		canonical, err := opts.Source.ReadRule(rule)
+		if err != nil {
+			fmt.Fprintf(opts.Stdout, "Skipping %s: no canonical rule in %s\n", entry.Name(), opts.Source.Dir)
+			continue
+		}
+		dst := filepath.Join(opts.DestRulesPath, entry.Name())
+		local, err := os.ReadFile(dst)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", dst, err)
+		}
+		if !bytes.Equal(local, canonical) {
+			fmt.Fprintf(opts.Stdout, "%s differs from the canonical rule:\n", entry.Name())
+			fmt.Fprint(opts.Stdout, utils.UnifiedDiff(opts.Source.Path(rule), dst, canonical, local))
+			if !opts.Force {
+				fmt.Fprintf(opts.Stdout, "[ai-rules-link] Skipping %s. Use --force to replace it with a symlink anyway.\n", entry.Name())
+				continue
+			}
+		}
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("remove %s: %w", dst, err)
+		}
+		err = SymlinkRules(ctx, SymlinkOptions{
+			Rules:         []string{rule},
+			CanonicalDir:  opts.Source.Dir,
+			DestRulesPath: opts.DestRulesPath,
+			Relative:      opts.Relative,
+			Stdout:        opts.Stdout,
+			Stderr:        opts.Stderr,
+		})
+		if err != nil {
+			return err
+		}
+		if opts.Manifest != nil {
+			e, ok := opts.Manifest.Lookup(dst)
+			if !ok {
+				e = ManifestEntry{Path: dst, Rules: []string{rule}}
+			}
+			e.Mode, e.Source, e.SHA256 = mode, opts.Manifest.sourceLabel(opts.Source), ""
+			opts.Manifest.Record(e)
+		}
+	}
+	return nil
+}
+
+func selected(rules []string, rule string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	for _, r := range rules {
+		if strings.EqualFold(r, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestEjectRules_ReplacesSymlinksWithCopies(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	project := t.TempDir()
+	dest := filepath.Join(project, ".cursor", "rules")
+	os.MkdirAll(dest, 0755)
+	os.Symlink(filepath.Join(canon, "gorules.mdc"), filepath.Join(dest, "gorules.mdc"))
+
+	manifest, _ := LoadManifest(project)
+	manifest.Record(ManifestEntry{Path: filepath.Join(dest, "gorules.mdc"), Rules: []string{"go"}, Mode: domain.ModeSymlink, Source: canon, Target: DefaultTarget, Global: true})
+	err := EjectRules(context.Background(), EjectOptions{
+		Manifest: manifest,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(dest, "gorules.mdc"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("expected a regular file, got %v, %v", info, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "gorules.mdc")); string(got) != "go" {
+		t.Errorf("unexpected content: %q", got)
+	}
+	e, ok := manifest.Lookup(filepath.Join(dest, "gorules.mdc"))
+	if !ok || e.Mode != domain.ModeCopy || e.Path != ".cursor/rules/gorules.mdc" || e.SHA256 != ContentHash([]byte("go")) || e.Target != DefaultTarget || !e.Global {
+		t.Errorf("unexpected manifest entry: %+v, %v", e, ok)
+	}
+}
+
+func TestEjectRules_OnlyRecordedSymlinks(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go", "pythonrules.mdc": "python"})
+	project := t.TempDir()
+	dest := filepath.Join(project, ".cursor", "rules")
+	pkg := filepath.Join(project, "backend", ".cursor", "rules")
+	os.MkdirAll(dest, 0755)
+	os.MkdirAll(pkg, 0755)
+	os.Symlink(filepath.Join(canon, "pythonrules.mdc"), filepath.Join(dest, "pythonrules.mdc"))
+	os.Symlink(filepath.Join(canon, "gorules.mdc"), filepath.Join(pkg, "gorules.mdc"))
+
+	manifest, _ := LoadManifest(project)
+	manifest.Record(ManifestEntry{Path: filepath.Join(pkg, "gorules.mdc"), Rules: []string{"go"}, Mode: domain.ModeRelSymlink, Source: canon, Target: DefaultTarget})
+	err := EjectRules(context.Background(), EjectOptions{
+		Manifest: manifest,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Lstat(filepath.Join(pkg, "gorules.mdc")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("expected the recorded package link to be ejected, got %v, %v", info, err)
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "pythonrules.mdc")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the user's own symlink to be left alone, got %v, %v", info, err)
+	}
+	if _, ok := manifest.Lookup(filepath.Join(dest, "pythonrules.mdc")); ok {
+		t.Errorf("the user's own symlink should not be recorded")
+	}
+}
+
+func TestAdoptRules_LinksMatchingCopies(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go", "pythonrules.mdc": "python"})
+	dest := t.TempDir()
+	os.WriteFile(filepath.Join(dest, "gorules.mdc"), []byte("go"), 0644)
+	os.WriteFile(filepath.Join(dest, "pythonrules.mdc"), []byte("python, edited\n"), 0644)
+
+	var out bytes.Buffer
+	err := AdoptRules(context.Background(), AdoptOptions{
+		Source:        DirSource("test", canon),
+		DestRulesPath: dest,
+		Stdout:        &out,
+		Stderr:        io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "gorules.mdc")); err != nil || target != filepath.Join(canon, "gorules.mdc") {
+		t.Errorf("expected matching copy to become a symlink, got %q, %v", target, err)
+	}
+	if info, _ := os.Lstat(filepath.Join(dest, "pythonrules.mdc")); !info.Mode().IsRegular() {
+		t.Errorf("differing copy should not be adopted without --force")
+	}
+	if !strings.Contains(out.String(), "+python, edited") {
+		t.Errorf("expected a diff for the differing copy, got:\n%s", out.String())
+	}
+}
+
+func TestAdoptRules_ForceAdoptsDifferingCopy(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	dest := t.TempDir()
+	os.WriteFile(filepath.Join(dest, "gorules.mdc"), []byte("edited"), 0644)
+	err := AdoptRules(context.Background(), AdoptOptions{
+		Source:        DirSource("test", canon),
+		DestRulesPath: dest,
+		Relative:      true,
+		Force:         true,
+		Stdout:        io.Discard,
+		Stderr:        io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	target, err := os.Readlink(filepath.Join(dest, "gorules.mdc"))
+	if err != nil || filepath.IsAbs(target) {
+		t.Errorf("expected a relative symlink, got %q, %v", target, err)
+	}
+}
+
+func TestManifest_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.Record(ManifestEntry{Path: filepath.Join(dir, "a", "gorules.mdc"), Rules: []string{"go"}, Mode: domain.ModeCopy})
+	if err := m.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Path != "a/gorules.mdc" {
+		t.Errorf("unexpected entries: %+v", loaded.Entries)
+	}
+	loaded.Forget("a/gorules.mdc")
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFilename)); !os.IsNotExist(err) {
+		t.Errorf("expected empty manifest to be removed, got %v", err)
+	}
+}
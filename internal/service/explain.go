@@ -0,0 +1,300 @@
+package service
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// Verdict is whether an installed rule is attached to a file.
+type Verdict string
+
+const (
+	// VerdictApplies means the tool attaches the rule whenever the file is involved.
+	VerdictApplies Verdict = "applies"
+	// VerdictMaybe means the model decides from the rule's description.
+	VerdictMaybe Verdict = "maybe"
+	// VerdictManual means the rule is attached only when mentioned explicitly.
+	VerdictManual Verdict = "manual"
+	// VerdictSkipped means the rule is read but does not cover the file.
+	VerdictSkipped Verdict = "skipped"
+	// VerdictShadowed means another copy of the same rule for the same tool is the one that counts.
+	VerdictShadowed Verdict = "shadowed"
+	// VerdictIgnored means the tool never reads the rule, e.g. because the file is missing or malformed.
+	VerdictIgnored Verdict = "ignored"
+)
+
+// Explanation is the verdict for one installed rule.
+type Explanation struct {
+	Target string
+	Rule   string
+	// Path is the installed file the rule was read from, relative to the project.
+	Path    string
+	Verdict Verdict
+	Reason  string
+	// pkg is the package directory the rule is limited to; "" is the whole project.
+	pkg string
+}
+
+// ExplainOptions configures ExplainFile.
+type ExplainOptions struct {
+	Manifest *Manifest
+	Embedded fs.FS // used for entries installed from the embedded rules
+	// File is the file to explain, relative to the manifest directory.
+	File string
+}
+
+// ExplainFile reports, for every rule installed in the project, whether it would be attached to
+// File and why. Cursor's rules directories are read from disk, including rules ai-rules-link did
+// not install; other targets are explained from the rules recorded in the manifest, as rendered.
+func ExplainFile(opts ExplainOptions) ([]Explanation, error) {
+	file := path.Clean(filepath.ToSlash(opts.File))
+	if file == "." || file == ".." || strings.HasPrefix(file, "../") || path.IsAbs(file) {
+		return nil, fmt.Errorf("%s is not a file in the project", opts.File)
+	}
+	return explainInstalled(loadInstalledRules(opts.Manifest, opts.Embedded), file), nil
+}
+
+// installedRule is a rule as one target reads it, prepared for evaluating many files.
+type installedRule struct {
+	// Explanation holds the rule's identity; Verdict and Reason are set when the tool ignores it.
+	Explanation
+	rule  domain.Rule
+	globs []domain.Glob
+	// noMetadata means the target keeps every rule in context, with its scope only as a note.
+	noMetadata bool
+	// cursor means Cursor's semantics, which ignore the globs of always-applied rules.
+	cursor bool
+}
+
+// explainInstalled evaluates every installed rule for file.
+func explainInstalled(installed []installedRule, file string) []Explanation {
+	explanations := make([]Explanation, len(installed))
+	for i, ir := range installed {
+		explanations[i] = ir.Explanation
+		if ir.Verdict == "" {
+			explanations[i].Verdict, explanations[i].Reason = ir.evaluate(file)
+		}
+	}
+	markShadowed(explanations)
+	sort.SliceStable(explanations, func(i, j int) bool { return explanations[i].Target < explanations[j].Target })
+	return explanations
+}
+
+// loadInstalledRules returns the rules installed in the project for every target: Cursor's rules
+// directories as they are on disk, then the other targets' manifest entries.
+func loadInstalledRules(m *Manifest, embedded fs.FS) []installedRule {
+	installed := loadCursorRules(m)
+	for _, e := range m.Entries {
+		if entryTarget(e) == DefaultTarget || e.Global {
+			continue
+		}
+		installed = append(installed, loadEntryRules(m, e, embedded)...)
+	}
+	return installed
+}
+
+// loadCursorRules reads every file in the project's Cursor rules directories: .cursor/rules and
+// the directories the manifest recorded Cursor installs in, such as those of monorepo packages.
+func loadCursorRules(m *Manifest) []installedRule {
+	dirs := map[string]string{".cursor/rules": ""} // rules directory -> package it is read for
+	for _, e := range m.Entries {
+		if entryTarget(e) != DefaultTarget || e.Global {
+			continue
+		}
+		if _, ok := dirs[path.Dir(e.Path)]; !ok {
+			pkg := e.Dir
+			if e.Scoped {
+				pkg = ""
+			}
+			dirs[path.Dir(e.Path)] = pkg
+		}
+	}
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+
+	var out []installedRule
+	for _, dir := range names {
+		entries, err := os.ReadDir(filepath.Join(m.Dir(), filepath.FromSlash(dir)))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			rel := path.Join(dir, entry.Name())
+			ir := installedRule{Explanation: Explanation{Target: DefaultTarget, Rule: cursorRuleName(entry.Name()), Path: rel, pkg: dirs[dir]}, cursor: true}
+			if !strings.HasSuffix(entry.Name(), ".mdc") {
+				ir.Verdict, ir.Reason = VerdictIgnored, "Cursor only reads .mdc files"
+				out = append(out, ir)
+				continue
+			}
+			raw, err := os.ReadFile(filepath.Join(m.Dir(), filepath.FromSlash(rel)))
+			if err != nil {
+				ir.Verdict, ir.Reason = VerdictIgnored, fmt.Sprintf("cannot be read: %v", err)
+				out = append(out, ir)
+				continue
+			}
+			r, err := domain.ParseRule(ir.Rule, raw)
+			if err != nil {
+				ir.Verdict, ir.Reason = VerdictIgnored, err.Error()
+				out = append(out, ir)
+				continue
+			}
+			out = append(out, ir.with(r))
+		}
+	}
+	return out
+}
+
+// loadEntryRules returns the rules a non-Cursor manifest entry was rendered from, as rendered.
+func loadEntryRules(m *Manifest, e ManifestEntry, embedded fs.FS) []installedRule {
+	target := entryTarget(e)
+	ignored := func(reason string) []installedRule {
+		return []installedRule{{Explanation: Explanation{Target: target, Rule: strings.Join(e.Rules, ", "), Path: e.Path, Verdict: VerdictIgnored, Reason: reason}}}
+	}
+	if _, err := os.Stat(m.Abs(e)); err != nil {
+		return ignored("installed file is missing")
+	}
+	tool, err := lookupEntryTool(e)
+	if err != nil {
+		return ignored(err.Error())
+	}
+	patches, err := entryPatches(m, e)
+	if err != nil {
+		return ignored(err.Error())
+	}
+	src := m.entrySource(e, embedded)
+	var out []installedRule
+	for _, name := range e.Rules {
+		ir := installedRule{Explanation: Explanation{Target: target, Rule: name, Path: e.Path, pkg: readForDir(e.Path)}, noMetadata: len(tool.Layout().Frontmatter) == 0}
+		r, err := src.LoadRuleSpec(name)
+		if err == nil {
+			r, err = patchRule(r, patches)
+		}
+		if err != nil {
+			ir.Verdict, ir.Reason = VerdictIgnored, fmt.Sprintf("cannot load %s to explain it: %v", RuleFilename(name), err)
+			out = append(out, ir)
+			continue
+		}
+		rules := []domain.Rule{r}
+		if e.AutoGlobs {
+			rules = autoGlobRules(rules)
+		}
+		if e.Scoped {
+			rules = scopeRules(rules, e.Dir)
+		}
+		out = append(out, ir.with(rules[0]))
+	}
+	return out
+}
+
+// with sets the rule ir evaluates and compiles its globs.
+func (ir installedRule) with(r domain.Rule) installedRule {
+	ir.rule = r
+	for _, g := range r.Globs {
+		ir.globs = append(ir.globs, domain.CompileGlob(g))
+	}
+	return ir
+}
+
+// evaluate decides whether the rule attaches to file, a project-relative path. Rules read for a
+// package match their globs relative to it.
+func (ir installedRule) evaluate(file string) (Verdict, string) {
+	if ir.pkg != "" {
+		if !underDir(file, ir.pkg) {
+			return VerdictSkipped, fmt.Sprintf("only read for files under %s/", ir.pkg)
+		}
+		file = strings.TrimPrefix(file, ir.pkg+"/")
+	}
+	r := ir.rule
+	if ir.noMetadata {
+		reason := fmt.Sprintf("%s has no rule metadata, so the rule is always in context", ir.Target)
+		if note := ScopeNote(r); note != "" {
+			reason += " with the note: " + note
+		}
+		return VerdictApplies, reason
+	}
+	switch r.Activation() {
+	case domain.ActivationAlways:
+		if len(r.Globs) > 0 && ir.cursor {
+			return VerdictApplies, "alwaysApply: true (its globs " + strings.Join(r.Globs, ", ") + " are ignored)"
+		}
+		return VerdictApplies, "alwaysApply: true"
+	case domain.ActivationGlob:
+		for i, g := range ir.globs {
+			if g.Match(file) {
+				return VerdictApplies, "matches " + r.Globs[i]
+			}
+		}
+		return VerdictSkipped, "matches none of " + strings.Join(r.Globs, ", ")
+	case domain.ActivationModelDecision:
+		return VerdictMaybe, fmt.Sprintf("attached when the model finds it relevant: %q", r.Description)
+	default:
+		return VerdictManual, "no globs or description; attached only when mentioned, e.g. @" + r.Name
+	}
+}
+
+// nearestFileTargets are the targets whose tool reads only the file nearest to the one it works on,
+// such as the agents' AGENTS.md. Other tools add nested rules to the root ones, e.g. Cursor reads
+// both .cursor/rules and a package's.
+var nearestFileTargets = map[string]bool{"agents": true}
+
+// markShadowed keeps one attached copy of each rule per nearest-file target, the one limited to
+// the deepest package, and marks the other copies as shadowed by it.
+func markShadowed(explanations []Explanation) {
+	best := map[string]int{}
+	for i, x := range explanations {
+		if !nearestFileTargets[x.Target] || x.Verdict != VerdictApplies && x.Verdict != VerdictMaybe {
+			continue
+		}
+		key := x.Target + "\x00" + x.Rule
+		j, ok := best[key]
+		if !ok {
+			best[key] = i
+			continue
+		}
+		winner, loser := j, i
+		if len(x.pkg) > len(explanations[j].pkg) {
+			winner, loser = i, j
+		}
+		best[key] = winner
+		explanations[loser].Verdict = VerdictShadowed
+		explanations[loser].Reason = "the copy in " + explanations[winner].Path + " takes precedence"
+	}
+}
+
+// cursorRuleName returns the rule name for a file in a Cursor rules directory.
+func cursorRuleName(filename string) string {
+	if name, ok := RuleName(filename); ok {
+		return name
+	}
+	return strings.TrimSuffix(filename, path.Ext(filename))
+}
+
+// readForDir returns the directory whose files a tool reads an installed file for: the directories
+// above its first hidden one, e.g. "web" for web/AGENTS.md or services/api/.windsurf/rules/go.md.
+func readForDir(installed string) string {
+	var dirs []string
+	for _, s := range strings.Split(path.Dir(installed), "/") {
+		if strings.HasPrefix(s, ".") {
+			break
+		}
+		dirs = append(dirs, s)
+	}
+	return strings.Join(dirs, "/")
+}
+
+func underDir(file, dir string) bool {
+	return strings.HasPrefix(file, dir+"/")
+}
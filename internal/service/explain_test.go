@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestExplainFile(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc":   "---\ndescription: Base\nglobs:\nalwaysApply: true\n---\nBase\n",
+		"gorules.mdc":     "---\ndescription: Go\nglobs: **/*.go\nalwaysApply: false\n---\nGo\n",
+		"pythonrules.mdc": "---\ndescription: Python\nglobs: *.py\nalwaysApply: false\n---\nPy\n",
+		"reviewrules.mdc": "---\ndescription: Code review checklist\nglobs:\nalwaysApply: false\n---\nReview\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	agents, _ := LookupTarget("agents")
+	install := func(target domain.Target, dir string, rules ...string) {
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: rules, Mode: domain.ModeCopy, Source: DirSource("test", canon), Target: target,
+			BaseDir: project, Dir: dir, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	}
+	install(nil, "", "base", "go", "python", "review")
+	install(nil, "services/api", "go")
+	install(agents, "", "base")
+	install(agents, "services/api", "base")
+	os.WriteFile(filepath.Join(project, ".cursor", "rules", "notes.md"), []byte("notes"), 0644)
+
+	explanations, err := ExplainFile(ExplainOptions{Manifest: manifest, File: "services/api/main.go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]Verdict{}
+	for _, x := range explanations {
+		got[x.Target+" "+x.Path] = x.Verdict
+	}
+	want := map[string]Verdict{
+		"agents AGENTS.md":                              VerdictShadowed,
+		"agents services/api/AGENTS.md":                 VerdictApplies,
+		"cursor .cursor/rules/baserules.mdc":            VerdictApplies,
+		"cursor .cursor/rules/gorules.mdc":              VerdictApplies,
+		"cursor services/api/.cursor/rules/gorules.mdc": VerdictApplies,
+		"cursor .cursor/rules/pythonrules.mdc":          VerdictSkipped,
+		"cursor .cursor/rules/reviewrules.mdc":          VerdictMaybe,
+		"cursor .cursor/rules/notes.md":                 VerdictIgnored,
+	}
+	for key, verdict := range want {
+		if got[key] != verdict {
+			t.Errorf("%s: got %q, want %q", key, got[key], verdict)
+		}
+	}
+
+	explanations, _ = ExplainFile(ExplainOptions{Manifest: manifest, File: "web/page.py"})
+	for _, x := range explanations {
+		if x.Path == "services/api/.cursor/rules/gorules.mdc" && x.Verdict != VerdictSkipped {
+			t.Errorf("package rule should not apply outside its package: %+v", x)
+		}
+		if x.Rule == "python" && x.Verdict != VerdictApplies {
+			t.Errorf("*.py should match at any depth: %+v", x)
+		}
+	}
+
+	if _, err := ExplainFile(ExplainOptions{Manifest: manifest, File: "../outside.go"}); err == nil {
+		t.Error("expected an error for a file outside the project")
+	}
+}
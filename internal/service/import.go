@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// ImportFiles are the hand-written instruction files import looks for, relative to the project.
+var ImportFiles = []string{
+	".cursorrules",
+	"CLAUDE.md",
+	filepath.Join(".github", "copilot-instructions.md"),
+	".windsurfrules",
+	"AGENTS.md",
+}
+
+// ImportOptions configures ImportRules.
+type ImportOptions struct {
+	ProjectDir string
+	// Files to import, relative to ProjectDir; empty imports every ImportFiles entry that exists.
+	Files []string
+	// Prefix starts every rule name, so rules from many repos can share one rules directory.
+	Prefix string
+	Dir    string // rules directory the rules are written to
+	Force  bool   // overwrite existing rule files
+	DryRun bool   // report what would be written without writing
+	Stdout io.Writer
+}
+
+// ImportedRule is one rule created by ImportRules.
+type ImportedRule struct {
+	Name string
+	Path string
+	// From is the instruction file the rule came from, relative to the project.
+	From string
+	// Heading is the heading the rule was split at; empty for the text before the first heading.
+	Heading string
+}
+
+// ImportRules splits existing instruction files into one always-applied rule per top-level
+// heading and writes them as <name>rules.mdc files into Dir. Managed blocks written by
+// ai-rules-link are skipped, since they came from rules already.
+func ImportRules(ctx context.Context, opts ImportOptions) ([]ImportedRule, error) {
+	files := opts.Files
+	if len(files) == 0 {
+		for _, f := range ImportFiles {
+			if _, err := os.Stat(filepath.Join(opts.ProjectDir, f)); err == nil {
+				files = append(files, f)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no instruction files found in %s (looked for %s)", opts.ProjectDir, strings.Join(ImportFiles, ", "))
+	}
+
+	// Plan every rule before writing, so a name clash does not leave a half-finished import.
+	var imported []ImportedRule
+	var parts []instructionPart
+	used := map[string]bool{}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(opts.ProjectDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		for _, part := range splitInstructions(string(utils.RemoveManagedBlock(data))) {
+			heading := part.Heading
+			if heading == "" {
+				// Text before the first section is named after its file, e.g. "claude" for CLAUDE.md.
+				heading = strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "."), filepath.Ext(file))
+			}
+			name := uniqueName(ruleSlug(opts.Prefix, heading), used)
+			path := filepath.Join(opts.Dir, RuleFilename(name))
+			if _, err := os.Stat(path); err == nil && !opts.Force {
+				return nil, fmt.Errorf("%s already exists; use --force to overwrite it or --prefix to pick other names", path)
+			}
+			imported = append(imported, ImportedRule{Name: name, Path: path, From: file, Heading: part.Heading})
+			parts = append(parts, part)
+		}
+	}
+	for i, r := range imported {
+		if err := ctx.Err(); err != nil {
+			return imported[:i], err
+		}
+		if err := writeImportedRule(r, parts[i], opts); err != nil {
+			return imported[:i], err
+		}
+	}
+	return imported, nil
+}
+
+// instructionPart is a piece of an instruction file that becomes one rule.
+type instructionPart struct {
+	Heading string
+	Text    string
+}
+
+// splitInstructions splits an instruction file at its top-level headings. A single title heading
+// and the text before the first section form their own part.
+func splitInstructions(text string) []instructionPart {
+	level := domain.SectionLevel(text)
+	if level == 0 {
+		return nonEmptyParts(instructionPart{Text: text})
+	}
+	preamble, sections := domain.SplitSections(text, level)
+	parts := []instructionPart{{Text: preamble}}
+	for _, s := range sections {
+		if s.Level < level {
+			// A title above the sections: keep it with the preamble.
+			parts[0].Text += s.Text
+			continue
+		}
+		parts = append(parts, instructionPart{Heading: s.Heading, Text: s.Text})
+	}
+	return nonEmptyParts(parts...)
+}
+
+func nonEmptyParts(parts ...instructionPart) []instructionPart {
+	var out []instructionPart
+	for _, p := range parts {
+		if strings.TrimSpace(p.Text) != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ruleSlug builds a rule name from a prefix and a heading, e.g. ("acme", "Testing Rules") ->
+// "acme-testing".
+func ruleSlug(prefix, heading string) string {
+	name := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(prefix+" "+heading), "-"), "-")
+	name = strings.Trim(strings.TrimSuffix(name, "rules"), "-")
+	if name == "" {
+		return "imported"
+	}
+	return name
+}
+
+// uniqueName returns name, or name with a numeric suffix when it was already used.
+func uniqueName(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// writeImportedRule writes one imported part with the house frontmatter.
+func writeImportedRule(r ImportedRule, part instructionPart, opts ImportOptions) error {
+	description := part.Heading
+	if description == "" {
+		description = "Imported from " + filepath.ToSlash(r.From)
+	}
+	content := fmt.Sprintf("---\ndescription: %s\nglobs:\nalwaysApply: true\n---\n\n%s", domain.FrontmatterValue(description), strings.TrimLeft(part.Text, "\n"))
+	content = strings.TrimRight(content, "\n") + "\n"
+	if opts.DryRun {
+		fmt.Fprintf(opts.Stdout, "Would write %s from %s\n", r.Path, r.From)
+		return nil
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", opts.Dir, err)
+	}
+	if err := os.WriteFile(r.Path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", r.Path, err)
+	}
+	fmt.Fprintf(opts.Stdout, "Imported %s from %s\n", r.Path, r.From)
+	return nil
+}
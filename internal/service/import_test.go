@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/utils"
+)
+
+func TestImportRules_SplitsByTopLevelHeading(t *testing.T) {
+	project := t.TempDir()
+	claude := "# Acme API\n\nUse Go 1.24.\n\n## Testing Rules\n\nTable tests.\n\n### Fixtures\n\nIn testdata/.\n\n## Style\n\nBe terse.\n\n" +
+		string(utils.ReplaceManagedBlock(nil, []byte("@.claude/rules/go.md\n")))
+	os.WriteFile(filepath.Join(project, "CLAUDE.md"), []byte(claude), 0644)
+	os.WriteFile(filepath.Join(project, ".cursorrules"), []byte("Always answer in English.\n"), 0644)
+	dir := t.TempDir()
+
+	imported, err := ImportRules(context.Background(), ImportOptions{ProjectDir: project, Prefix: "acme", Dir: dir, Stdout: io.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, r := range imported {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, " "); got != "acme-cursor acme-claude acme-testing acme-style" {
+		t.Fatalf("unexpected rules: %s", got)
+	}
+	testing, err := DirSource("test", dir).LoadRule("acme-testing")
+	if err != nil {
+		t.Fatalf("imported rule does not load: %v", err)
+	}
+	if !testing.AlwaysApply || testing.Description != "Testing Rules" || testing.Body != "## Testing Rules\n\nTable tests.\n\n### Fixtures\n\nIn testdata/.\n" {
+		t.Errorf("unexpected rule: %+v", testing)
+	}
+	title, _ := os.ReadFile(filepath.Join(dir, "acme-clauderules.mdc"))
+	if !strings.Contains(string(title), "# Acme API\n\nUse Go 1.24.\n") || strings.Contains(string(title), "@.claude") {
+		t.Errorf("unexpected title rule:\n%s", title)
+	}
+
+	if _, err := ImportRules(context.Background(), ImportOptions{ProjectDir: project, Prefix: "acme", Dir: dir, Stdout: io.Discard}); err == nil {
+		t.Error("expected an error when rules already exist")
+	}
+}
+
+func TestImportRules_QuotesDescriptions(t *testing.T) {
+	project := t.TempDir()
+	os.WriteFile(filepath.Join(project, "CLAUDE.md"), []byte("# Acme\n\n## Setup: Docker\n\nUse compose.\n\n## #1 Rule\n\nBe terse.\n"), 0644)
+	dir := t.TempDir()
+
+	if _, err := ImportRules(context.Background(), ImportOptions{ProjectDir: project, Prefix: "acme", Dir: dir, Stdout: io.Discard}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, want := range map[string]string{"acme-setup-docker": "Setup: Docker", "acme-1-rule": "#1 Rule"} {
+		r, err := DirSource("test", dir).LoadRule(name)
+		if err != nil {
+			t.Errorf("imported rule %s does not load: %v", name, err)
+			continue
+		}
+		if r.Description != want {
+			t.Errorf("%s: description %q, want %q", name, r.Description, want)
+		}
+		if !strings.Contains(string(r.Raw), "description: '"+want+"'\n") {
+			t.Errorf("%s: description is not quoted for YAML readers:\n%s", name, r.Raw)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// ConsolidatedFilename is the file written by the consolidate install mode.
+const ConsolidatedFilename = "consolidatedrules.mdc"
+
+// consolidatedMarkdown is the consolidated file for targets that read .md rule files.
+const consolidatedMarkdown = "consolidatedrules.md"
+
+// InstallOptions configures InstallRules.
+type InstallOptions struct {
+	Rules  []string
+	Mode   domain.InstallMode // empty picks symlink for directory sources and copy for embedded rules
+	Source RuleSource
+	// Target renders the rules for one AI tool; nil installs for Cursor.
+	Target domain.Target
+	// BaseDir is the directory target paths are relative to: the project, or home with --global.
+	BaseDir string
+	// Global installs into the target's user-level location; BaseDir must be the home directory.
+	Global bool
+	// Dir is a package of a monorepo, relative to BaseDir, to install for. Its rules go into the
+	// package's own rules directories, or stay in BaseDir limited to the package when Scoped is set.
+	Dir    string
+	Scoped bool
+	// AutoGlobs limits always-applied language rules to the files of their language; see AutoGlobTarget.
+	AutoGlobs bool
+	Force     bool      // overwrite destination files that have been modified by the user
+	Manifest  *Manifest // when set, installed files are recorded here; the caller saves it
+	Stdout    io.Writer
+	Stderr    io.Writer
+}
+
+// ErrRuleNotFound is returned by InstallRules, after installing the rules that were found, when
+// selected rules are missing from the source.
+var ErrRuleNotFound = errors.New("rule not found")
+
+// DefaultMode returns the mode used when none is requested explicitly.
+func DefaultMode(src RuleSource) domain.InstallMode {
+	if src.Embedded() {
+		return domain.ModeCopy
+	}
+	return domain.ModeSymlink
+}
+
+// InstallRules renders the selected rules from Source for Target and installs the resulting files
+// under BaseDir using the requested mode. Files a target generates, rather than passes through
+// unchanged, are always written as copies. Project installs apply the patches in BaseDir's
+// overrides file, so patched rules are copies too.
+func InstallRules(ctx context.Context, opts InstallOptions) error {
+	if len(opts.Rules) == 0 {
+		fmt.Fprintln(opts.Stderr, "No rules specified. Use --rule for each rule you want to install (e.g., --rule=go --rule=base)")
+		return fmt.Errorf("no rules specified")
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = DefaultMode(opts.Source)
+	}
+	if mode.IsLink() && opts.Source.Embedded() {
+		return fmt.Errorf("mode %s needs a rules directory to link to; embedded rules can only be copied or consolidated", mode)
+	}
+	target := opts.Target
+	if target == nil {
+		target, _ = LookupTarget(DefaultTarget)
+	}
+	if opts.Global {
+		var ok bool
+		if target, ok = UserTarget(target); !ok {
+			fmt.Fprintf(opts.Stderr, "[ai-rules-link] %s has no user-level rules location; installing into %s anyway, where it may not be read.\n", target.Name(), opts.BaseDir)
+		}
+	}
+	var patches []domain.Patch
+	if !opts.Global {
+		var err error
+		if patches, err = LoadOverrides(opts.BaseDir); err != nil {
+			return err
+		}
+	}
+	configured := target
+	if opts.Dir != "" {
+		if opts.Scoped {
+			target = ScopedTarget{Target: target, Dir: opts.Dir}
+		} else {
+			opts.BaseDir = filepath.Join(opts.BaseDir, filepath.FromSlash(opts.Dir))
+		}
+	}
+	if opts.AutoGlobs {
+		target = AutoGlobTarget{Target: target}
+	}
+
+	var rules []domain.Rule
+	var missing []string
+	specs := map[string]string{} // rule name -> selection, to record the sections taken
+	for _, spec := range mergeRuleSpecs(opts.Rules) {
+		name, _ := domain.ParseRuleSpec(spec)
+		r, err := opts.Source.LoadRuleSpec(spec)
+		if errors.Is(err, fs.ErrNotExist) {
+			if mode == domain.ModeConsolidate {
+				return fmt.Errorf("could not read %s: %w", RuleFilename(name), err)
+			}
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if r, err = patchRule(r, patches); err != nil {
+			return err
+		}
+		if n := rulePatches(patches, r.Name); n > 0 {
+			fmt.Fprintf(opts.Stdout, "[ai-rules-link] Applied %d patch(es) from %s to %s.\n", n, OverridesFilename, r.Name)
+		}
+		specs[r.Name] = spec
+		rules = append(rules, r)
+	}
+	var notFound error
+	if len(missing) > 0 {
+		notFound = fmt.Errorf("%w in %s rules: %s", ErrRuleNotFound, opts.Source.Name, strings.Join(missing, ", "))
+	}
+	if len(rules) == 0 {
+		return notFound
+	}
+	files, err := target.Render(rules, domain.RenderOptions{Consolidate: mode == domain.ModeConsolidate, BaseDir: opts.BaseDir})
+	if err != nil {
+		return fmt.Errorf("render %s rules: %w", target.Name(), err)
+	}
+	if mode.IsLink() && opts.Mode != "" {
+		var generated []string
+		for _, f := range files {
+			if f.LinkSource == "" && !f.Managed {
+				generated = append(generated, f.Path)
+			}
+		}
+		switch len(generated) {
+		case 0:
+		case 1:
+			fmt.Fprintf(opts.Stdout, "[ai-rules-link] %s is generated for %s, so it is copied instead of linked.\n", generated[0], target.Name())
+		default:
+			fmt.Fprintf(opts.Stdout, "[ai-rules-link] %s are generated for %s, so they are copied instead of linked.\n", strings.Join(generated, ", "), target.Name())
+		}
+	}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for i, name := range f.Rules {
+			if spec, ok := specs[name]; ok {
+				f.Rules[i] = spec
+			}
+		}
+		if err := installFile(f, mode, target, opts); err != nil {
+			return err
+		}
+	}
+	if err := opts.removeStale(files, target); err != nil {
+		return err
+	}
+	if c, ok := configured.(domain.ConfiguredTarget); ok {
+		changed, err := c.Configure(opts.BaseDir)
+		if err != nil {
+			return fmt.Errorf("configure %s: %w", target.Name(), err)
+		}
+		if changed {
+			fmt.Fprintf(opts.Stdout, "Updated %s configuration to read its rules\n", target.Name())
+		}
+	}
+	return notFound
+}
+
+// mergeRuleSpecs combines selections of the same rule, so "go#testing" and "go#style" become
+// "go#testing#style". Selecting a rule without sections takes all of it.
+func mergeRuleSpecs(specs []string) []string {
+	var names []string
+	sections := map[string][]string{}
+	whole := map[string]bool{}
+	for _, spec := range specs {
+		name, s := domain.ParseRuleSpec(spec)
+		if _, seen := sections[name]; !seen && !whole[name] {
+			names = append(names, name)
+			sections[name] = nil
+		}
+		if len(s) == 0 {
+			whole[name] = true
+		}
+		sections[name] = append(sections[name], s...)
+	}
+	merged := make([]string, len(names))
+	for i, name := range names {
+		merged[i] = name
+		if !whole[name] {
+			merged[i] = strings.Join(append([]string{name}, sections[name]...), "#")
+		}
+	}
+	return merged
+}
+
+// installFile writes one rendered file, linking it to its source when the mode and file allow.
+func installFile(f domain.TargetFile, mode domain.InstallMode, target domain.Target, opts InstallOptions) error {
+	dst := filepath.Join(opts.BaseDir, f.Path)
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	name := filepath.Base(dst)
+	if f.Warning != "" {
+		fmt.Fprintf(opts.Stderr, "[ai-rules-link] Warning: %s\n", f.Warning)
+	}
+	if mode.IsLink() && f.LinkSource == "" {
+		mode = domain.ModeCopy
+	}
+
+	switch mode {
+	case domain.ModeSymlink, domain.ModeRelSymlink:
+		linkTarget := f.LinkSource
+		if mode == domain.ModeRelSymlink {
+			rel, err := relativeLink(dir, f.LinkSource)
+			if err != nil {
+				fmt.Fprintf(opts.Stderr, "Failed to compute relative path for %s: %v\n", name, err)
+				return nil
+			}
+			linkTarget = rel
+		}
+		if current, err := os.Readlink(dst); err == nil && current == linkTarget {
+			fmt.Fprintf(opts.Stdout, "Symlink for %s already exists and is correct.\n", name)
+		} else {
+			os.Remove(dst)
+			if err := os.Symlink(linkTarget, dst); err != nil {
+				fmt.Fprintf(opts.Stderr, "Failed to create symlink for %s: %v\n", name, err)
+				return nil
+			}
+			fmt.Fprintf(opts.Stdout, "Symlinked %s into %s\n", name, dir)
+		}
+		opts.record(dst, f, target, mode, nil)
+	case domain.ModeHardlink:
+		srcInfo, err := os.Stat(f.LinkSource)
+		if err != nil {
+			fmt.Fprintf(opts.Stderr, "Canonical rules file does not exist: %s\n", f.LinkSource)
+			return nil
+		}
+		if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+			fmt.Fprintf(opts.Stdout, "Hard link for %s already exists and is correct.\n", name)
+		} else {
+			if !opts.Force && opts.modifiedByUser(dst, f.Content, false) {
+				fmt.Fprintf(opts.Stdout, "[ai-rules-link] Skipping %s: destination file has been modified by the user. Use --force to overwrite.\n", dst)
+				return nil
+			}
+			os.Remove(dst)
+			if err := os.Link(f.LinkSource, dst); err != nil {
+				fmt.Fprintf(opts.Stderr, "Failed to create hard link for %s: %v\n", name, err)
+				return nil
+			}
+			fmt.Fprintf(opts.Stdout, "Hard linked %s into %s\n", name, dir)
+		}
+		opts.record(dst, f, target, mode, nil)
+	default:
+		if !opts.Force && opts.modifiedByUser(dst, f.Content, f.Managed) {
+			fmt.Fprintf(opts.Stdout, "[ai-rules-link] Skipping %s: destination file has been modified by the user. Use --force to overwrite.\n", dst)
+			return nil
+		}
+		if err := writeTargetFile(dst, f.Content, f.Managed); err != nil {
+			if mode == domain.ModeConsolidate {
+				return fmt.Errorf("failed to write consolidated file: %w", err)
+			}
+			fmt.Fprintf(opts.Stderr, "Failed to copy rule %s: %v\n", name, err)
+			return nil
+		}
+		opts.record(dst, f, target, mode, f.Content)
+		switch {
+		case f.Managed:
+			fmt.Fprintf(opts.Stdout, "Updated managed block in %s\n", dst)
+		case mode == domain.ModeConsolidate:
+			fmt.Fprintf(opts.Stdout, "Consolidated rules written to: %s\n", dst)
+		default:
+			fmt.Fprintf(opts.Stdout, "Copied %s %s into %s\n", opts.Source.Name, name, dir)
+		}
+	}
+	return nil
+}
+
+// record notes an installed file in the manifest, if one was given. Content is hashed for written files.
+func (opts InstallOptions) record(path string, f domain.TargetFile, target domain.Target, mode domain.InstallMode, content []byte) {
+	if opts.Manifest == nil {
+		return
+	}
+	e := ManifestEntry{Path: path, Rules: f.Rules, Mode: mode, Source: opts.Manifest.sourceLabel(opts.Source), Target: target.Name(), Managed: f.Managed, Global: opts.Global, Dir: opts.Dir, Scoped: opts.Scoped && opts.Dir != "", AutoGlobs: opts.AutoGlobs}
+	if content != nil {
+		e.SHA256 = ContentHash(content)
+	}
+	opts.Manifest.Record(e)
+}
+
+// removeStale deletes the files the manifest records for the same rules, target and directory that
+// the latest render no longer produces, such as the extra parts of a Windsurf rule that shrank.
+func (opts InstallOptions) removeStale(files []domain.TargetFile, target domain.Target) error {
+	if opts.Manifest == nil {
+		return nil
+	}
+	rendered := map[string]bool{}
+	dirs := map[string]bool{} // rules and directory of each rendered file
+	for _, f := range files {
+		dst := filepath.Join(opts.BaseDir, f.Path)
+		rendered[dst] = true
+		dirs[strings.Join(f.Rules, ",")+"\x00"+filepath.Dir(dst)] = true
+	}
+	scoped := opts.Scoped && opts.Dir != ""
+	for _, e := range append([]ManifestEntry{}, opts.Manifest.Entries...) {
+		if entryTarget(e) != target.Name() || e.Global != opts.Global || e.Dir != opts.Dir || e.Scoped != scoped {
+			continue
+		}
+		dst := opts.Manifest.Abs(e)
+		if rendered[dst] || !dirs[strings.Join(e.Rules, ",")+"\x00"+filepath.Dir(dst)] {
+			continue
+		}
+		if err := removeStaleEntry(opts.Manifest, e, target, opts.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeStaleEntry deletes a file the target no longer generates and forgets it, unless it was
+// modified since it was installed.
+func removeStaleEntry(m *Manifest, e ManifestEntry, target domain.Target, stdout io.Writer) error {
+	dst := m.Abs(e)
+	if modifiedSinceInstall(dst, e) {
+		fmt.Fprintf(stdout, "[ai-rules-link] Keeping %s: %s no longer generates it, but it was modified since it was installed.\n", e.Path, target.Name())
+		return nil
+	}
+	if err := removeInstalled(dst, e.Managed); err != nil {
+		return err
+	}
+	m.Forget(e.Path)
+	fmt.Fprintf(stdout, "Removed %s, which %s no longer generates\n", e.Path, target.Name())
+	return nil
+}
+
+// modifiedByUser reports whether the content ai-rules-link owns at dst differs from want and from
+// what ai-rules-link last wrote there according to the manifest. For managed files only the managed
+// block is considered.
+func (opts InstallOptions) modifiedByUser(dst string, want []byte, managed bool) bool {
+	if info, err := os.Lstat(dst); err != nil || !managed && !info.Mode().IsRegular() {
+		return false
+	}
+	got, err := installedContent(dst, managed)
+	if err != nil || bytes.Equal(got, want) {
+		return false
+	}
+	if opts.Manifest != nil {
+		if e, ok := opts.Manifest.Lookup(dst); ok && e.SHA256 == ContentHash(got) {
+			return false
+		}
+	}
+	return true
+}
+
+// installedContent returns the part of dst that ai-rules-link owns: the whole file, or only the
+// managed block for managed files.
+func installedContent(dst string, managed bool) ([]byte, error) {
+	data, err := os.ReadFile(dst)
+	if err != nil || !managed {
+		return data, err
+	}
+	block, ok := utils.ExtractManagedBlock(data)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return block, nil
+}
+
+// writeTargetFile writes content to dst. Managed content replaces only the managed block, keeping
+// hand-written text around it.
+func writeTargetFile(dst string, content []byte, managed bool) error {
+	if !managed {
+		return writeInstalled(dst, content)
+	}
+	existing, err := os.ReadFile(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", dst, err)
+	}
+	if err := os.WriteFile(dst, utils.ReplaceManagedBlock(existing, content), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"ai-rules-link/internal/domain"
+)
+
+func newCanonicalDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInstallRules_RelSymlink(t *testing.T) {
+	root := t.TempDir()
+	canon := filepath.Join(root, "ai-rules")
+	os.MkdirAll(canon, 0755)
+	os.WriteFile(filepath.Join(canon, "gorules.mdc"), []byte("go"), 0644)
+	dest := filepath.Join(root, "project", ".cursor", "rules")
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:   []string{"go"},
+		Mode:    domain.ModeRelSymlink,
+		Source:  DirSource("test", canon),
+		BaseDir: filepath.Join(root, "project"),
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	target, err := os.Readlink(filepath.Join(dest, "gorules.mdc"))
+	if err != nil {
+		t.Fatalf("expected symlink: %v", err)
+	}
+	if filepath.IsAbs(target) {
+		t.Errorf("expected relative target, got %s", target)
+	}
+	if want := filepath.Join("..", "..", "..", "ai-rules", "gorules.mdc"); target != want {
+		t.Errorf("target mismatch: got %s, want %s", target, want)
+	}
+	content, err := os.ReadFile(filepath.Join(dest, "gorules.mdc"))
+	if err != nil || string(content) != "go" {
+		t.Errorf("relative symlink does not resolve: %q, %v", content, err)
+	}
+}
+
+func TestInstallRules_Hardlink(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	base := t.TempDir()
+	dest := filepath.Join(base, ".cursor", "rules")
+	opts := InstallOptions{
+		Rules:   []string{"go"},
+		Mode:    domain.ModeHardlink,
+		Source:  DirSource("test", canon),
+		BaseDir: base,
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	}
+	if err := InstallRules(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srcInfo, _ := os.Stat(filepath.Join(canon, "gorules.mdc"))
+	dstInfo, err := os.Lstat(filepath.Join(dest, "gorules.mdc"))
+	if err != nil {
+		t.Fatalf("expected hard link: %v", err)
+	}
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Errorf("destination is not a hard link to the canonical file")
+	}
+}
+
+func TestInstallRules_CopySkipsModified(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	base := t.TempDir()
+	dest := filepath.Join(base, ".cursor", "rules")
+	dst := filepath.Join(dest, "gorules.mdc")
+	os.MkdirAll(dest, 0755)
+	os.WriteFile(dst, []byte("user edit"), 0644)
+	opts := InstallOptions{
+		Rules:   []string{"go"},
+		Mode:    domain.ModeCopy,
+		Source:  DirSource("test", canon),
+		BaseDir: base,
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	}
+	if err := InstallRules(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "user edit" {
+		t.Errorf("copy overwrote a user-modified file: %q", got)
+	}
+	opts.Force = true
+	if err := InstallRules(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "go" {
+		t.Errorf("forced copy did not overwrite: %q", got)
+	}
+}
+
+func TestInstallRules_ConsolidateEmbedded(t *testing.T) {
+	embedded := fstest.MapFS{
+		"rules/gorules.mdc":     {Data: []byte("go content")},
+		"rules/pythonrules.mdc": {Data: []byte("python content")},
+	}
+	base := t.TempDir()
+	dest := filepath.Join(base, ".cursor", "rules")
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:   []string{"go", "python"},
+		Mode:    domain.ModeConsolidate,
+		Source:  EmbeddedSource(embedded),
+		BaseDir: base,
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dest, ConsolidatedFilename))
+	if string(got) != "go content\npython content\n" {
+		t.Errorf("unexpected consolidated content: %q", got)
+	}
+}
+
+func TestInstallRules_LinkModeRejectsEmbedded(t *testing.T) {
+	embedded := fstest.MapFS{"rules/gorules.mdc": {Data: []byte("go")}}
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:   []string{"go"},
+		Mode:    domain.ModeSymlink,
+		Source:  EmbeddedSource(embedded),
+		BaseDir: t.TempDir(),
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	})
+	if err == nil || !strings.Contains(err.Error(), "needs a rules directory") {
+		t.Errorf("expected link mode to be rejected for embedded rules, got: %v", err)
+	}
+}
+
+func TestParseInstallMode(t *testing.T) {
+	if m, err := domain.ParseInstallMode("relsymlink"); err != nil || m != domain.ModeRelSymlink {
+		t.Errorf("unexpected result: %v, %v", m, err)
+	}
+	if _, err := domain.ParseInstallMode("bogus"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestInstallRules_Sections(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"gorules.mdc": "---\ndescription: Go\nglobs:\nalwaysApply: true\n---\n\n## Concurrency\n\nChannels.\n\n### Mutexes\n\nSmall.\n\n## Testing\n\nTables.\n\n## Style\n\ngofmt.\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"go#concurrency", "go#Testing"}, Mode: domain.ModeSymlink, Source: DirSource("test", canon),
+		BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(project, ".cursor", "rules", "gorules.mdc"))
+	want := "---\ndescription: Go\nglobs:\nalwaysApply: true\nsections: concurrency, testing\n---\n\n## Concurrency\n\nChannels.\n\n### Mutexes\n\nSmall.\n\n## Testing\n\nTables.\n"
+	if err != nil || string(got) != want {
+		t.Errorf("got %q, %v\nwant %q", got, err, want)
+	}
+	e, ok := manifest.Lookup(".cursor/rules/gorules.mdc")
+	if !ok || e.Mode != domain.ModeCopy || len(e.Rules) != 1 || e.Rules[0] != "go#concurrency#Testing" {
+		t.Errorf("sections not recorded: %+v", e)
+	}
+	if drift, _, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest}); err != nil || len(drift) != 0 {
+		t.Errorf("sectioned install reported as drifted: %+v, %v", drift, err)
+	}
+
+	err = InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"go#generics"}, Mode: domain.ModeCopy, Source: DirSource("test", canon),
+		BaseDir: project, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err == nil {
+		t.Error("expected an error for a missing section")
+	}
+}
+
+func TestInstallRules_MissingRule(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	project := t.TempDir()
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base", "go"}, Mode: domain.ModeSymlink, Source: DirSource("test", canon),
+		BaseDir: project, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if !errors.Is(err, ErrRuleNotFound) || !strings.Contains(err.Error(), "base") {
+		t.Errorf("expected ErrRuleNotFound naming base, got %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); err != nil {
+		t.Errorf("the rule that exists was not installed: %v", err)
+	}
+}
+
+func TestInstallRules_CopyNoticeOncePerTarget(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"gorules.mdc":     "---\ndescription: Go\nglobs: *.go\nalwaysApply: false\n---\nGo\n",
+		"pythonrules.mdc": "---\ndescription: Python\nglobs: *.py\nalwaysApply: false\n---\nPython\n",
+	})
+	install := func(target string, mode domain.InstallMode) string {
+		tgt, _ := LookupTarget(target)
+		var stdout bytes.Buffer
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: []string{"go", "python"}, Mode: mode, Source: DirSource("test", canon), Target: tgt,
+			BaseDir: t.TempDir(), Stdout: &stdout, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install %s: %v", target, err)
+		}
+		return stdout.String()
+	}
+	if out := install("claude", ""); strings.Contains(out, "instead of linked") {
+		t.Errorf("default mode should not explain copies:\n%s", out)
+	}
+	if out := install("windsurf", domain.ModeSymlink); strings.Count(out, "instead of linked") != 1 {
+		t.Errorf("expected one notice for windsurf:\n%s", out)
+	}
+	if out := install("claude", domain.ModeSymlink); strings.Count(out, "instead of linked") != 1 || strings.Contains(out, "CLAUDE.md, ") || strings.Contains(out, "CLAUDE.md is") {
+		t.Errorf("expected one notice for claude, without its managed CLAUDE.md:\n%s", out)
+	}
+}
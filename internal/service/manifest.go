@@ -0,0 +1,195 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// ManifestFilename is the file, kept in the project root, that records what ai-rules-link installed.
+const ManifestFilename = ".ai-rules-link.json"
+
+// ManifestEntry records one installed file.
+type ManifestEntry struct {
+	// Path is the installed file, relative to the manifest directory.
+	Path string `json:"path"`
+	// Rules lists the rules the file was generated from.
+	Rules []string `json:"rules"`
+	// Mode is how the file was installed.
+	Mode domain.InstallMode `json:"mode"`
+	// Source is where the file's rules came from: "embedded", a user rules directory by its name
+	// such as "~/ai-rules", or a directory relative to the manifest directory such as ".ai-rules",
+	// so the manifest stays valid in another checkout. Older manifests hold absolute paths.
+	Source string `json:"source"`
+	// Target is the AI tool the file was rendered for; empty means Cursor.
+	Target string `json:"target,omitempty"`
+	// Managed means only a managed block inside Path belongs to ai-rules-link.
+	Managed bool `json:"managed,omitempty"`
+	// SHA256 is the hash of the content written, for copies and consolidated files.
+	// For managed files it covers the managed block only.
+	SHA256 string `json:"sha256,omitempty"`
+	// Global means the file was installed into the target's user-level location.
+	Global bool `json:"global,omitempty"`
+	// Dir is the monorepo package the file was installed for, relative to the manifest directory;
+	// empty means the project itself.
+	Dir string `json:"dir,omitempty"`
+	// Scoped means the file was installed at the project root with its rules limited to Dir.
+	Scoped bool `json:"scoped,omitempty"`
+	// AutoGlobs means always-applied language rules were limited to files of their language.
+	AutoGlobs bool `json:"autoGlobs,omitempty"`
+}
+
+// Manifest is the set of files installed into a project.
+type Manifest struct {
+	Entries []ManifestEntry `json:"entries"`
+
+	dir string
+}
+
+// LoadManifest reads the manifest in dir. A missing manifest yields an empty one.
+func LoadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Dir returns the directory the manifest lives in.
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// Record adds or replaces the entry for the given absolute or manifest-relative path.
+func (m *Manifest) Record(e ManifestEntry) {
+	e.Path = m.rel(e.Path)
+	for i := range m.Entries {
+		if m.Entries[i].Path == e.Path {
+			m.Entries[i] = e
+			return
+		}
+	}
+	m.Entries = append(m.Entries, e)
+}
+
+// Lookup returns the entry for path, if any.
+func (m *Manifest) Lookup(path string) (ManifestEntry, bool) {
+	path = m.rel(path)
+	for _, e := range m.Entries {
+		if e.Path == path {
+			return e, true
+		}
+	}
+	return ManifestEntry{}, false
+}
+
+// Forget drops the entry for path.
+func (m *Manifest) Forget(path string) {
+	path = m.rel(path)
+	for i := range m.Entries {
+		if m.Entries[i].Path == path {
+			m.Entries = append(m.Entries[:i], m.Entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Abs returns the absolute path of an entry.
+func (m *Manifest) Abs(e ManifestEntry) string {
+	if filepath.IsAbs(e.Path) {
+		return e.Path
+	}
+	return filepath.Join(m.dir, e.Path)
+}
+
+// Base returns the absolute directory an entry's target paths are relative to: its package, unless
+// the entry was scoped to the package from the project root.
+func (m *Manifest) Base(e ManifestEntry) string {
+	if e.Scoped {
+		return m.dir
+	}
+	return filepath.Join(m.dir, filepath.FromSlash(e.Dir))
+}
+
+// Save writes the manifest back to disk, or removes it once it is empty.
+func (m *Manifest) Save() error {
+	path := filepath.Join(m.dir, ManifestFilename)
+	if len(m.Entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove manifest: %w", err)
+		}
+		return nil
+	}
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+func (m *Manifest) rel(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(m.dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// ContentHash returns the hex SHA-256 of content, as stored in manifest entries.
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// sourceLabel is the value recorded in ManifestEntry.Source for a rule source.
+func (m *Manifest) sourceLabel(src RuleSource) string {
+	if src.Embedded() {
+		return "embedded"
+	}
+	for _, u := range userSources() {
+		if filepath.Clean(u.Dir) == filepath.Clean(src.Dir) {
+			return u.Name
+		}
+	}
+	if rel, err := filepath.Rel(m.dir, src.Dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return src.Dir
+}
+
+// entrySource returns the rule source a manifest entry was installed from, as found on this machine.
+func (m *Manifest) entrySource(e ManifestEntry, embedded fs.FS) RuleSource {
+	switch {
+	case e.Source == "embedded":
+		return EmbeddedSource(embedded)
+	case filepath.IsAbs(e.Source):
+		return DirSource(e.Source, e.Source)
+	}
+	for _, u := range userSources() {
+		if u.Name == e.Source {
+			return u
+		}
+	}
+	return DirSource(e.Source, filepath.Join(m.dir, filepath.FromSlash(e.Source)))
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// LegacyCursorrules is the single rules file Cursor read before .cursor/rules/.
+const LegacyCursorrules = ".cursorrules"
+
+// ProjectRuleName is the rule that keeps migrated content no canonical rule covers.
+const ProjectRuleName = "project"
+
+// MinSimilarity is how alike a legacy section and a rule must be to count as a near-duplicate.
+const MinSimilarity = 0.9
+
+// MigrateOptions configures MigrateCursorrules.
+type MigrateOptions struct {
+	ProjectDir string
+	Source     RuleSource         // canonical rules sections are matched against
+	Mode       domain.InstallMode // how matched rules are installed; empty uses DefaultMode
+	Target     domain.Target      // a Cursor target, e.g. with another rules directory; nil uses Cursor's default
+	Manifest   *Manifest
+	Force      bool // overwrite an existing project rule
+	DryRun     bool // report the migration without changing anything
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
+// MigrateMatch is a section of the legacy file that matched a canonical rule.
+type MigrateMatch struct {
+	Section    string // the section heading, or the first line when it has none
+	Rule       string
+	Similarity float64 // 1 for an exact match
+}
+
+// MigrateReport describes what MigrateCursorrules did, or would do in a dry run.
+type MigrateReport struct {
+	Matches []MigrateMatch
+	// Unmatched lists the sections written to ProjectRule.
+	Unmatched []string
+	// ProjectRule is the .mdc holding unmatched content; empty when everything matched.
+	ProjectRule string
+	// Backup is where the legacy file was moved.
+	Backup string
+}
+
+// MigrateCursorrules replaces a legacy .cursorrules file with .cursor/rules/, and fails for other
+// targets. Sections that match
+// a canonical rule exactly or nearly are installed as that rule, the rest is written to
+// projectrules.mdc, and the legacy file is moved to a backup.
+func MigrateCursorrules(ctx context.Context, opts MigrateOptions) (MigrateReport, error) {
+	var report MigrateReport
+	legacy := filepath.Join(opts.ProjectDir, LegacyCursorrules)
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return report, fmt.Errorf("no %s in %s", LegacyCursorrules, opts.ProjectDir)
+		}
+		return report, fmt.Errorf("read %s: %w", legacy, err)
+	}
+	target := opts.Target
+	if target == nil {
+		target, _ = LookupTarget("cursor")
+	}
+	if target.Name() != "cursor" {
+		// The project rule is written with Cursor's frontmatter, which other tools do not read.
+		return report, fmt.Errorf("%s is Cursor's legacy file and can only be migrated to Cursor rules, not %s; use import for other tools", LegacyCursorrules, target.Name())
+	}
+	rules, err := loadAllRules(opts.Source)
+	if err != nil {
+		return report, err
+	}
+
+	var matched []string
+	var rest []string
+	report.Matches, report.Unmatched, rest = matchLegacy(strings.ReplaceAll(string(data), "\r\n", "\n"), rules)
+	for _, m := range report.Matches {
+		matched = append(matched, m.Rule)
+	}
+	if len(rest) > 0 {
+		report.ProjectRule = filepath.Join(opts.ProjectDir, target.Layout().Dir, RuleFilename(ProjectRuleName))
+		if _, err := os.Stat(report.ProjectRule); err == nil && !opts.Force {
+			return report, fmt.Errorf("%s already exists; use --force to overwrite it", report.ProjectRule)
+		}
+	}
+	report.Backup = backupPath(legacy)
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if len(matched) > 0 {
+		err := InstallRules(ctx, InstallOptions{
+			Rules:    matched,
+			Mode:     opts.Mode,
+			Source:   opts.Source,
+			Target:   target,
+			BaseDir:  opts.ProjectDir,
+			Manifest: opts.Manifest,
+			Stdout:   opts.Stdout,
+			Stderr:   opts.Stderr,
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if report.ProjectRule != "" {
+		content := "---\ndescription: Project-specific rules migrated from " + LegacyCursorrules + "\nglobs:\nalwaysApply: true\n---\n\n" + strings.Join(rest, "\n\n") + "\n"
+		if err := os.MkdirAll(filepath.Dir(report.ProjectRule), 0755); err != nil {
+			return report, fmt.Errorf("create %s: %w", filepath.Dir(report.ProjectRule), err)
+		}
+		if err := os.WriteFile(report.ProjectRule, []byte(content), 0644); err != nil {
+			return report, fmt.Errorf("write %s: %w", report.ProjectRule, err)
+		}
+		if opts.Manifest != nil {
+			// The project rule is its own source, so sync and check find it unchanged.
+			opts.Manifest.Record(ManifestEntry{
+				Path:   report.ProjectRule,
+				Rules:  []string{ProjectRuleName},
+				Mode:   domain.ModeCopy,
+				Source: opts.Manifest.sourceLabel(DirSource("", filepath.Dir(report.ProjectRule))),
+				Target: target.Name(),
+				SHA256: ContentHash([]byte(content)),
+			})
+		}
+	}
+	if err := os.Rename(legacy, report.Backup); err != nil {
+		return report, fmt.Errorf("back up %s: %w", legacy, err)
+	}
+	return report, nil
+}
+
+// loadAllRules loads every rule in src.
+func loadAllRules(src RuleSource) ([]domain.Rule, error) {
+	names, err := src.ListRules()
+	if err != nil {
+		return nil, fmt.Errorf("list %s rules: %w", src.Name, err)
+	}
+	rules := make([]domain.Rule, 0, len(names))
+	for _, name := range names {
+		r, err := src.LoadRule(name)
+		if err != nil {
+			return nil, fmt.Errorf("load rule %s: %w", name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// legacyParagraph is a paragraph of a legacy rules file and the rule found to contain it.
+type legacyParagraph struct {
+	text    string
+	part    int // index of the instruction part it belongs to
+	heading bool
+	rule    string
+	// extra holds the lines of a claimed paragraph that are not in the rule, such as a note
+	// appended without a blank line.
+	extra string
+}
+
+// matchLegacy finds the canonical rules whose bodies text contains, paragraph by paragraph, so a
+// file that concatenates several rules is recognized whether or not it has headings. A rule
+// matches when at least MinSimilarity of its body is found. It returns the matches in file order,
+// and the label and text of every part with paragraphs no rule claimed.
+func matchLegacy(text string, rules []domain.Rule) (matches []MigrateMatch, unmatched, rest []string) {
+	parts := splitInstructions(text)
+	var paras []legacyParagraph
+	for i, part := range parts {
+		for _, p := range splitParagraphs(part.Text) {
+			paras = append(paras, legacyParagraph{text: p, part: i, heading: isHeadingLine(p)})
+		}
+	}
+
+	first := map[string]int{} // rule -> its first paragraph, to order the matches
+	for _, r := range rules {
+		found, of, score := findRule(paras, r)
+		if score < MinSimilarity {
+			continue
+		}
+		for k, i := range found {
+			paras[i].rule = r.Name
+			paras[i].extra = extraLines(paras[i].text, of[k])
+		}
+		start := slices.Min(found)
+		if i := start - 1; i >= 0 && paras[i].rule == "" && paras[i].text == ruleFrontmatter(r) {
+			paras[i].rule = r.Name
+		}
+		label := parts[paras[start].part].Heading
+		if label == "" {
+			label = firstLine(paras[start].text)
+		}
+		first[r.Name] = start
+		matches = append(matches, MigrateMatch{Section: label, Rule: r.Name, Similarity: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return first[matches[i].Rule] < first[matches[j].Rule] })
+
+	for i, part := range parts {
+		var left []string
+		claimed, onlyHeadings := false, true
+		for _, p := range paras {
+			switch {
+			case p.part != i:
+			case p.rule != "":
+				claimed = true
+				if p.extra != "" {
+					left = append(left, p.extra)
+					onlyHeadings = false
+				}
+			default:
+				left = append(left, p.text)
+				onlyHeadings = onlyHeadings && p.heading
+			}
+		}
+		if len(left) == 0 || claimed && onlyHeadings {
+			continue
+		}
+		label := part.Heading
+		if label == "" {
+			label = firstLine(left[0])
+		}
+		unmatched = append(unmatched, label)
+		rest = append(rest, strings.Join(left, "\n\n"))
+	}
+	return matches, unmatched, rest
+}
+
+// findRule looks for each paragraph of r's body among the unclaimed paragraphs, preferring the
+// ones after the previous find so repeated paragraphs are taken from the rule's own copy. It
+// returns the paragraphs found, the rule paragraph each matched, and the share of the body they
+// cover.
+func findRule(paras []legacyParagraph, r domain.Rule) ([]int, []string, float64) {
+	total, covered := 0.0, 0.0
+	var found []int
+	var of []string
+	used := map[int]bool{}
+	next := 0
+	for _, b := range splitParagraphs(r.Body) {
+		total += float64(len(b))
+		best, bestScore := -1, 0.0
+		for k := range paras {
+			i := (next + k) % len(paras)
+			if paras[i].rule != "" || used[i] {
+				continue
+			}
+			if s := utils.Similarity(b, paras[i].text); s >= MinSimilarity && s > bestScore {
+				best, bestScore = i, s
+				if s == 1 {
+					break
+				}
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		used[best] = true
+		found = append(found, best)
+		of = append(of, b)
+		next = best + 1
+		covered += bestScore * float64(len(b))
+	}
+	if total == 0 || len(found) == 0 {
+		return nil, nil, 0
+	}
+	return found, of, covered / total
+}
+
+// extraLines returns the lines of text that are like no line of the rule paragraph it matched.
+func extraLines(text, rule string) string {
+	ruleLines := strings.Split(rule, "\n")
+	var extra []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		found := false
+		for _, r := range ruleLines {
+			if utils.Similarity(line, r) >= MinSimilarity {
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, line)
+		}
+	}
+	return strings.Join(extra, "\n")
+}
+
+// splitParagraphs splits markdown at blank lines outside fenced code blocks. Headings are
+// paragraphs of their own.
+func splitParagraphs(text string) []string {
+	var paras []string
+	var current []string
+	flush := func() {
+		if p := strings.Trim(strings.Join(current, "\n"), "\n"); strings.TrimSpace(p) != "" {
+			paras = append(paras, p)
+		}
+		current = nil
+	}
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case trimmed == "":
+			flush()
+			continue
+		case trimmed == "---":
+			// A frontmatter delimiter of a pasted rule file: the block is a paragraph of its own.
+			if len(current) > 0 && strings.TrimSpace(current[0]) == "---" {
+				current = append(current, line)
+				flush()
+				continue
+			}
+			flush()
+		case isHeadingLine(line):
+			flush()
+			current = append(current, line)
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return paras
+}
+
+func isHeadingLine(text string) bool {
+	return !strings.Contains(text, "\n") && strings.HasPrefix(text, "#") && domain.SectionLevel(text) > 0
+}
+
+// ruleFrontmatter returns the frontmatter block of r's file, as a file concatenating rules with
+// cat would contain it, or "" when it has none.
+func ruleFrontmatter(r domain.Rule) string {
+	raw := strings.ReplaceAll(string(r.Raw), "\r\n", "\n")
+	if !strings.HasPrefix(raw, "---\n") {
+		return ""
+	}
+	end := strings.Index(raw[4:], "\n---")
+	if end < 0 {
+		return ""
+	}
+	return raw[:4+end+4]
+}
+
+// backupPath returns a path next to path that does not exist yet: path.bak, then path.bak.2 and so on.
+func backupPath(path string) string {
+	candidate := path + ".bak"
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.bak.%d", path, i)
+	}
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestMigrateCursorrules(t *testing.T) {
+	goBody := "Use gofmt on every file.\nWrap errors with %w and context.\nPrefer table-driven tests for functions with many cases.\n"
+	canon := newCanonicalDir(t, map[string]string{
+		"gorules.mdc":     "---\nglobs: *.go\nalwaysApply: false\n---\n" + goBody,
+		"pythonrules.mdc": "---\nalwaysApply: true\n---\nUse type hints.\n",
+	})
+	project := t.TempDir()
+	legacy := "## Go\n\n" + goBody + "\n## Deployment\n\nDeploy with make release.\n"
+	os.WriteFile(filepath.Join(project, ".cursorrules"), []byte(legacy), 0644)
+	manifest, _ := LoadManifest(project)
+	opts := MigrateOptions{
+		ProjectDir: project,
+		Source:     DirSource("test", canon),
+		Mode:       domain.ModeSymlink,
+		Manifest:   manifest,
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+
+	report, err := MigrateCursorrules(context.Background(), MigrateOptions{ProjectDir: project, Source: opts.Source, DryRun: true})
+	if err != nil || len(report.Matches) != 1 {
+		t.Fatalf("unexpected dry run: %+v, %v", report, err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".cursorrules")); err != nil {
+		t.Fatalf("dry run must not touch the legacy file: %v", err)
+	}
+
+	report, err = MigrateCursorrules(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := report.Matches[0]; m.Rule != "go" || m.Section != "Go" || m.Similarity < MinSimilarity {
+		t.Errorf("unexpected match: %+v", m)
+	}
+	if len(report.Unmatched) != 1 || report.Unmatched[0] != "Deployment" {
+		t.Errorf("unexpected unmatched sections: %v", report.Unmatched)
+	}
+	if link, err := os.Readlink(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); err != nil || link != filepath.Join(canon, "gorules.mdc") {
+		t.Errorf("expected go rule to be linked, got %q, %v", link, err)
+	}
+	r, err := DirSource("project", filepath.Join(project, ".cursor", "rules")).LoadRule(ProjectRuleName)
+	if err != nil || !r.AlwaysApply || r.Body != "## Deployment\n\nDeploy with make release.\n" {
+		t.Errorf("unexpected project rule: %+v, %v", r, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(project, ".cursorrules.bak")); err != nil || string(got) != legacy {
+		t.Errorf("expected a backup of the legacy file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".cursorrules")); !os.IsNotExist(err) {
+		t.Errorf("legacy file should be removed, got %v", err)
+	}
+	if _, ok := manifest.Lookup(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); !ok {
+		t.Error("installed rule should be recorded in the manifest")
+	}
+}
+
+func TestMigrateCursorrules_ConcatenatedRules(t *testing.T) {
+	rulesDir, _ := filepath.Abs(filepath.Join("..", "..", "rules"))
+	src := DirSource("rules", rulesDir)
+	// Rules pasted with a blank line between them, or concatenated as is with cat.
+	for name, sep := range map[string]string{"pasted": "\n", "cat": ""} {
+		t.Run(name, func(t *testing.T) {
+			var legacy strings.Builder
+			for _, rule := range []string{"base", "go", "python"} {
+				raw, err := src.ReadRule(rule)
+				if err != nil {
+					t.Fatalf("read shipped rule %s: %v", rule, err)
+				}
+				legacy.Write(raw)
+				legacy.WriteString(sep)
+			}
+			// A hand edit to the Go rule still matches; project-specific text stays behind.
+			text := strings.Replace(legacy.String(), "goroutines safely", "goroutines carefully", 1)
+			text += "Deploy with make release.\n"
+			project := t.TempDir()
+			os.WriteFile(filepath.Join(project, ".cursorrules"), []byte(text), 0644)
+			manifest, _ := LoadManifest(project)
+
+			report, err := MigrateCursorrules(context.Background(), MigrateOptions{
+				ProjectDir: project, Source: src, Mode: domain.ModeCopy, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, m := range report.Matches {
+				got = append(got, m.Rule)
+			}
+			if want := []string{"base", "go", "python"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("matched %v, want %v (unmatched: %q)", got, want, report.Unmatched)
+			}
+			r, err := DirSource("project", filepath.Join(project, ".cursor", "rules")).LoadRule(ProjectRuleName)
+			if err != nil || r.Body != "Deploy with make release.\n" {
+				t.Errorf("unexpected project rule: %q, %v", r.Body, err)
+			}
+			if e, ok := manifest.Lookup(report.ProjectRule); !ok || e.Mode != domain.ModeCopy {
+				t.Errorf("project rule not recorded: %+v", e)
+			}
+			if drift, _, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest}); err != nil || len(drift) != 0 {
+				t.Errorf("migrated project reported as drifted: %+v, %v", drift, err)
+			}
+		})
+	}
+}
+
+func TestMigrateCursorrules_OnlyCursor(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "Use gofmt.\n"})
+	project := t.TempDir()
+	os.WriteFile(filepath.Join(project, ".cursorrules"), []byte("Deploy with make release.\n"), 0644)
+	claude, _ := LookupTarget("claude")
+
+	_, err := MigrateCursorrules(context.Background(), MigrateOptions{
+		ProjectDir: project,
+		Source:     DirSource("test", canon),
+		Target:     claude,
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	})
+	if err == nil || !strings.Contains(err.Error(), "only be migrated to Cursor rules") {
+		t.Fatalf("expected migrate to refuse the claude target, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".cursorrules")); err != nil {
+		t.Errorf("the legacy file must be left alone: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".claude")); !os.IsNotExist(err) {
+		t.Errorf("nothing should be written for claude: %v", err)
+	}
+}
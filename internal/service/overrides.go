@@ -0,0 +1,218 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// OverridesFilename is the project file that patches rules as they are installed.
+const OverridesFilename = ".ai-rules-overrides.yaml"
+
+// LoadOverrides reads the patches in dir's overrides file; a missing file has none.
+func LoadOverrides(dir string) ([]domain.Patch, error) {
+	data, err := os.ReadFile(filepath.Join(dir, OverridesFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", OverridesFilename, err)
+	}
+	patches, err := ParseOverrides(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", OverridesFilename, err)
+	}
+	return patches, nil
+}
+
+// ParseOverrides parses the overrides format, a list of patches, each naming a rule, what to change
+// and one operation:
+//
+//	overrides:
+//	  - rule: base
+//	    directive: 7
+//	    expect: Explain on Request
+//	    replace: "**Explain Briefly:** Summarize each change in one line."
+//	  - rule: base
+//	    directive: 2
+//	    remove: true
+//	  - rule: go
+//	    heading: concurrency
+//	    append: |
+//	      Prefer errgroup over a bare sync.WaitGroup.
+//
+// Only that subset of YAML is understood: plain or quoted values, | blocks and # comments outside
+// quotes.
+func ParseOverrides(data []byte) ([]domain.Patch, error) {
+	var patches []domain.Patch
+	var current *domain.Patch
+	start := 0 // line of the current patch
+	inOverrides := false
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		n := i + 1
+		text := utils.StripYAMLComment(lines[i])
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(text)
+		if text[0] != ' ' && text[0] != '\t' {
+			key, value, _ := strings.Cut(trimmed, ":")
+			if strings.TrimSpace(key) != "overrides" || strings.TrimSpace(value) != "" {
+				return nil, fmt.Errorf("line %d: expected overrides: followed by a list of patches", n)
+			}
+			inOverrides = true
+			continue
+		}
+		if !inOverrides {
+			return nil, fmt.Errorf("line %d: unexpected indentation", n)
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if current != nil {
+				if err := validatePatch(*current); err != nil {
+					return nil, fmt.Errorf("line %d: %w", start, err)
+				}
+			}
+			patches = append(patches, domain.Patch{})
+			current, start = &patches[len(patches)-1], n
+			trimmed = strings.TrimSpace(trimmed[1:])
+			indent += 2
+			if trimmed == "" {
+				continue
+			}
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: expected a list item starting with -", n)
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "|" || value == "|-" {
+			var block []string
+			for i+1 < len(lines) {
+				next := lines[i+1]
+				if strings.TrimSpace(next) != "" && len(next)-len(strings.TrimLeft(next, " \t")) <= indent {
+					break
+				}
+				block = append(block, next)
+				i++
+			}
+			value = dedent(block)
+		} else {
+			value = unquoteValue(value)
+		}
+		if err := setPatchField(current, key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	if current != nil {
+		if err := validatePatch(*current); err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+	}
+	return patches, nil
+}
+
+func setPatchField(p *domain.Patch, key, value string) error {
+	setOp := func(op domain.PatchOp) error {
+		if p.Op != "" {
+			return fmt.Errorf("%s and %s in one patch; use one operation per patch", p.Op, op)
+		}
+		p.Op = op
+		return nil
+	}
+	switch key {
+	case "rule":
+		p.Rule = strings.ToLower(value)
+	case "heading":
+		p.Heading = value
+	case "directive":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("directive must be a number, got %q", value)
+		}
+		p.Directive = n
+	case "expect":
+		p.Expect = value
+	case string(domain.PatchRemove):
+		if value != "true" {
+			return fmt.Errorf("remove takes true, got %q", value)
+		}
+		return setOp(domain.PatchRemove)
+	case string(domain.PatchReplace), string(domain.PatchAppend), string(domain.PatchInsertAfter):
+		p.Text = value
+		return setOp(domain.PatchOp(key))
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return nil
+}
+
+func validatePatch(p domain.Patch) error {
+	switch {
+	case p.Rule == "":
+		return fmt.Errorf("patch without a rule")
+	case p.Op == "":
+		return fmt.Errorf("patch for %s has no operation (use replace, remove, append or insertAfter)", p.Rule)
+	case p.Op == domain.PatchRemove && p.Heading == "" && p.Directive == 0:
+		return fmt.Errorf("remove for %s needs a directive or a heading", p.Rule)
+	case p.Op != domain.PatchRemove && strings.TrimSpace(p.Text) == "":
+		return fmt.Errorf("%s for %s has no text", p.Op, p)
+	}
+	return nil
+}
+
+// dedent removes the indentation of a block's first non-blank line from all its lines.
+func dedent(block []string) string {
+	for len(block) > 0 && strings.TrimSpace(block[len(block)-1]) == "" {
+		block = block[:len(block)-1]
+	}
+	prefix := ""
+	for _, l := range block {
+		if strings.TrimSpace(l) != "" {
+			prefix = l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+			break
+		}
+	}
+	out := make([]string, len(block))
+	for i, l := range block {
+		out[i] = strings.TrimPrefix(l, prefix)
+	}
+	return strings.Join(out, "\n")
+}
+
+// patchRule applies the project's patches for r, naming the overrides file when one fails.
+func patchRule(r domain.Rule, patches []domain.Patch) (domain.Rule, error) {
+	r, err := domain.ApplyPatches(r, patches)
+	if err != nil {
+		return r, fmt.Errorf("%s: %w; update or remove the patch", OverridesFilename, err)
+	}
+	return r, nil
+}
+
+// rulePatches counts the patches for a rule.
+func rulePatches(patches []domain.Patch, rule string) int {
+	n := 0
+	for _, p := range patches {
+		if strings.EqualFold(p.Rule, rule) {
+			n++
+		}
+	}
+	return n
+}
+
+// entryPatches returns the patches for a manifest entry: the project's, unless it was installed globally.
+func entryPatches(m *Manifest, e ManifestEntry) ([]domain.Patch, error) {
+	if e.Global {
+		return nil, nil
+	}
+	return LoadOverrides(m.Dir())
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestParseOverrides(t *testing.T) {
+	data := []byte(`# project patches
+overrides:
+  - rule: Base
+    directive: 7
+    expect: "Explain on Request"
+    replace: "**Explain Briefly:** One line, see issue #42." # shorter
+  - rule: base
+    directive: 2
+    expect: 'Dependencies' # don't # stop here
+    remove: true
+  -
+    rule: go
+    heading: Concurrency
+    append: |
+      ## Notes
+
+        - Prefer errgroup.
+`)
+	got, err := ParseOverrides(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []domain.Patch{
+		{Rule: "base", Directive: 7, Expect: "Explain on Request", Op: domain.PatchReplace, Text: "**Explain Briefly:** One line, see issue #42."},
+		{Rule: "base", Directive: 2, Expect: "Dependencies", Op: domain.PatchRemove},
+		{Rule: "go", Heading: "Concurrency", Op: domain.PatchAppend, Text: "## Notes\n\n  - Prefer errgroup."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	for _, bad := range []string{
+		"patches:\n  - rule: base\n",
+		"overrides:\n  - directive: 2\n    remove: true\n",
+		"overrides:\n  - rule: base\n    directive: 2\n",
+		"overrides:\n  - rule: base\n    directive: seven\n    remove: true\n",
+		"overrides:\n  - rule: base\n    remove: true\n",
+		"overrides:\n  - rule: base\n    directive: 2\n    remove: true\n    append: x\n",
+		"overrides:\n  - rule: base\n    append:\n",
+	} {
+		if _, err := ParseOverrides([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestInstallRules_Overrides(t *testing.T) {
+	base := "---\ndescription: Base\nglobs:\nalwaysApply: true\n---\n\n1.  **Conventions:** Follow them.\n2.  **Explain on Request:** Only when asked.\n"
+	canon := newCanonicalDir(t, map[string]string{"baserules.mdc": base, "gorules.mdc": "---\ndescription: Go\nglobs:\nalwaysApply: true\n---\nGo\n"})
+	project := t.TempDir()
+	overrides := "overrides:\n  - rule: base\n    directive: 2\n    expect: Explain on Request\n    replace: \"**Explain Briefly:** One line.\"\n"
+	if err := os.WriteFile(filepath.Join(project, OverridesFilename), []byte(overrides), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := LoadManifest(project)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base", "go"}, Mode: domain.ModeSymlink, Source: DirSource("test", canon),
+		BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	dst := filepath.Join(project, ".cursor", "rules", "baserules.mdc")
+	got, _ := os.ReadFile(dst)
+	if want := strings.Replace(base, "**Explain on Request:** Only when asked.", "**Explain Briefly:** One line.", 1); string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if info, err := os.Lstat(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("a rule without patches should still be linked")
+	}
+	if drift, _, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest}); err != nil || len(drift) != 0 {
+		t.Errorf("patched install reported as drifted: %+v, %v", drift, err)
+	}
+
+	// The directive changes upstream: sync keeps the installed copy and reports the stale patch.
+	if err := os.WriteFile(filepath.Join(canon, "baserules.mdc"), []byte(strings.Replace(base, "Explain on Request", "Explain Always", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	if _, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: &stderr}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !strings.Contains(stderr.String(), `replace base directive 2 no longer applies: it does not contain "Explain on Request"`) {
+		t.Errorf("stale patch not reported: %q", stderr.String())
+	}
+	if again, _ := os.ReadFile(dst); !bytes.Equal(again, got) {
+		t.Error("sync overwrote the file despite the failed patch")
+	}
+	err = InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base"}, Mode: domain.ModeCopy, Source: DirSource("test", canon),
+		BaseDir: project, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err == nil || !strings.Contains(err.Error(), OverridesFilename) {
+		t.Errorf("expected the install to fail naming %s, got %v", OverridesFilename, err)
+	}
+}
+
+func TestInstallRules_OverridesPatchLabelledSection(t *testing.T) {
+	rulesDir, _ := filepath.Abs(filepath.Join("..", "..", "rules"))
+	project := t.TempDir()
+	overrides := "overrides:\n  - rule: go\n    heading: concurrency\n    expect: goroutines\n    append: |\n      Prefer errgroup over a bare sync.WaitGroup.\n"
+	if err := os.WriteFile(filepath.Join(project, OverridesFilename), []byte(overrides), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"go"}, Mode: domain.ModeCopy, Source: DirSource("rules", rulesDir),
+		BaseDir: project, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(project, ".cursor", "rules", "gorules.mdc"))
+	if !strings.Contains(string(got), "explain potential race conditions.\n    Prefer errgroup over a bare sync.WaitGroup.\n") {
+		t.Errorf("labelled section not patched:\n%s", got)
+	}
+}
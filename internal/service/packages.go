@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// PackagesFilename is the project file that maps the packages of a monorepo to their rules.
+const PackagesFilename = ".ai-rules.yaml"
+
+// Package scopes.
+const (
+	// ScopeNested installs each package's rules into the rules directories inside the package,
+	// which tools such as Cursor read for files under it.
+	ScopeNested = "nested"
+	// ScopeGlobs installs every package's rules at the project root, with globs limited to the package.
+	ScopeGlobs = "globs"
+)
+
+// Package is a project directory and the rules installed for it.
+type Package struct {
+	// Path is relative to the project root; "." is the root itself.
+	Path  string
+	Rules []string
+}
+
+// PackagesConfig is the content of .ai-rules.yaml:
+//
+//	scope: nested
+//	packages:
+//	  .: [base]
+//	  services/api: [go, docker]
+//	  web:
+//	    - nextjs
+type PackagesConfig struct {
+	// Scope is ScopeNested or ScopeGlobs; empty means ScopeNested.
+	Scope    string
+	Packages []Package
+}
+
+// LoadPackages reads .ai-rules.yaml from dir. It reports false when the file does not exist.
+func LoadPackages(dir string) (PackagesConfig, bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackagesFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return PackagesConfig{}, false, nil
+		}
+		return PackagesConfig{}, false, fmt.Errorf("read %s: %w", PackagesFilename, err)
+	}
+	c, err := ParsePackages(data)
+	if err != nil {
+		return c, false, fmt.Errorf("%s: %w", PackagesFilename, err)
+	}
+	return c, true, nil
+}
+
+// ParsePackages parses the .ai-rules.yaml format: a scope and a map from package path to a flow or
+// block list of rules. Only that subset of YAML is understood.
+func ParsePackages(data []byte) (PackagesConfig, error) {
+	var c PackagesConfig
+	inPackages := false
+	var current *Package
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		n := i + 1
+		text := utils.StripYAMLComment(line)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		indented := text[0] == ' ' || text[0] == '\t'
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case !indented:
+			key, value, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return c, fmt.Errorf("line %d: expected key: value", n)
+			}
+			inPackages, current = false, nil
+			switch strings.TrimSpace(key) {
+			case "scope":
+				c.Scope = unquoteValue(strings.TrimSpace(value))
+			case "packages":
+				if strings.TrimSpace(value) != "" {
+					return c, fmt.Errorf("line %d: packages must map directories to rules", n)
+				}
+				inPackages = true
+			default:
+				return c, fmt.Errorf("line %d: unknown key %q", n, strings.TrimSpace(key))
+			}
+		case !inPackages:
+			return c, fmt.Errorf("line %d: unexpected indentation", n)
+		case strings.HasPrefix(trimmed, "- ") || trimmed == "-":
+			if current == nil {
+				return c, fmt.Errorf("line %d: list item outside a package", n)
+			}
+			if rule := unquoteValue(strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))); rule != "" {
+				current.Rules = append(current.Rules, rule)
+			}
+		default:
+			key, value, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return c, fmt.Errorf("line %d: expected <directory>: [rules]", n)
+			}
+			dir, err := CleanPackagePath(unquoteValue(strings.TrimSpace(key)))
+			if err != nil {
+				return c, fmt.Errorf("line %d: %w", n, err)
+			}
+			c.Packages = append(c.Packages, Package{Path: dir})
+			current = &c.Packages[len(c.Packages)-1]
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+				return c, fmt.Errorf("line %d: rules must be a list", n)
+			}
+			for _, rule := range strings.Split(value[1:len(value)-1], ",") {
+				if rule = unquoteValue(strings.TrimSpace(rule)); rule != "" {
+					current.Rules = append(current.Rules, rule)
+				}
+			}
+		}
+	}
+	switch c.Scope {
+	case "", ScopeNested, ScopeGlobs:
+	default:
+		return c, fmt.Errorf("unknown scope %q (use %s or %s)", c.Scope, ScopeNested, ScopeGlobs)
+	}
+	return c, nil
+}
+
+// Marshal renders the config in the format ParsePackages reads.
+func (c PackagesConfig) Marshal() []byte {
+	var sb strings.Builder
+	sb.WriteString("# Rules per package, installed by `ai-rules-link rules`.\n")
+	scope := c.Scope
+	if scope == "" {
+		scope = ScopeNested
+	}
+	fmt.Fprintf(&sb, "# scope: %s writes each package's own rules directories; %s scopes root rules by glob.\n", ScopeNested, ScopeGlobs)
+	fmt.Fprintf(&sb, "scope: %s\npackages:\n", scope)
+	for _, p := range c.Packages {
+		fmt.Fprintf(&sb, "  %s: [%s]\n", p.Path, strings.Join(p.Rules, ", "))
+	}
+	return []byte(sb.String())
+}
+
+// PackageDir returns the manifest's Dir value for a package path: "" for the root.
+func PackageDir(p string) string {
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// CleanPackagePath normalizes a package path and rejects paths outside the project.
+func CleanPackagePath(p string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("empty package path")
+	}
+	p = path.Clean(filepath.ToSlash(p))
+	if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("package %s is outside the project", p)
+	}
+	return p, nil
+}
+
+func unquoteValue(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// ScopedTarget installs rules for a package at the project root, limiting them to the package with
+// domain.ScopeRule. Rule files get the package path as a prefix so packages do not overwrite each
+// other; a file shared by all rules, such as a root CLAUDE.md, cannot be scoped.
+type ScopedTarget struct {
+	domain.Target
+	// Dir is the package, relative to the project root.
+	Dir string
+}
+
+// Render implements domain.Target.
+func (t ScopedTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	files, err := t.Target.Render(scopeRules(rules, t.Dir), opts)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.ReplaceAll(t.Dir, "/", "-") + "-"
+	for i, f := range files {
+		if !f.Managed {
+			files[i].Path = filepath.Join(filepath.Dir(f.Path), prefix+filepath.Base(f.Path))
+			continue
+		}
+		if !strings.HasPrefix(filepath.ToSlash(f.Path), t.Dir+"/") {
+			return nil, fmt.Errorf("%s keeps every package's rules in %s; install %s with scope %s instead", t.Name(), f.Path, t.Dir, ScopeNested)
+		}
+	}
+	return files, nil
+}
+
+// scopeRules limits every rule to the package dir with domain.ScopeRule.
+func scopeRules(rules []domain.Rule, dir string) []domain.Rule {
+	scoped := make([]domain.Rule, len(rules))
+	for i, r := range rules {
+		scoped[i] = domain.ScopeRule(r, dir)
+	}
+	return scoped
+}
+
+// SyncPackagesOptions configures SyncPackages.
+type SyncPackagesOptions struct {
+	Config   PackagesConfig
+	Source   RuleSource
+	Manifest *Manifest
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// SyncPackages brings a project's installs in line with a changed .ai-rules.yaml. Every target
+// installed in the project is installed again for each package with the package's rules, in the
+// mode it was installed in. Files of rules dropped from a package, and of packages no longer listed,
+// are removed; the project's own files are left alone unless "." is listed. The caller saves the manifest.
+func SyncPackages(ctx context.Context, opts SyncPackagesOptions) error {
+	m := opts.Manifest
+	var targets []string
+	modes := map[string]domain.InstallMode{}
+	for _, e := range m.Entries {
+		if e.Global {
+			continue
+		}
+		name := entryTarget(e)
+		if _, ok := modes[name]; !ok {
+			targets = append(targets, name)
+			modes[name] = e.Mode
+		}
+		// Files a link install had to render are recorded as copies; the link mode wins.
+		if e.Mode.IsLink() {
+			modes[name] = e.Mode
+		}
+	}
+
+	wanted := map[string][]string{} // package dir -> rule names
+	for _, p := range opts.Config.Packages {
+		var names []string
+		for _, r := range p.Rules {
+			name, _ := domain.ParseRuleSpec(r)
+			names = append(names, name)
+		}
+		wanted[PackageDir(p.Path)] = names
+	}
+	for _, e := range append([]ManifestEntry{}, m.Entries...) {
+		if e.Global {
+			continue
+		}
+		rules, ok := wanted[e.Dir]
+		if !ok && e.Dir == "" || ok && len(rules) > 0 && allSelected(rules, e.Rules) {
+			continue
+		}
+		target, err := lookupEntryTarget(e)
+		if err != nil {
+			continue
+		}
+		if err := removeStaleEntry(m, e, target, opts.Stdout); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range opts.Config.Packages {
+		if len(p.Rules) == 0 {
+			continue
+		}
+		for _, name := range targets {
+			target, err := LookupTarget(name)
+			if err != nil {
+				return err
+			}
+			err = InstallRules(ctx, InstallOptions{
+				Rules: p.Rules, Mode: modes[name], Source: opts.Source, Target: target, BaseDir: m.Dir(),
+				Dir: PackageDir(p.Path), Scoped: opts.Config.Scope == ScopeGlobs, Manifest: m,
+				Stdout: opts.Stdout, Stderr: opts.Stderr,
+			})
+			if errors.Is(err, ErrRuleNotFound) {
+				fmt.Fprintf(opts.Stderr, "Package %s: %v\n", p.Path, err)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("package %s (%s): %w", p.Path, name, err)
+			}
+		}
+	}
+	return nil
+}
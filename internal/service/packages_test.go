@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestParsePackages(t *testing.T) {
+	data := []byte(`# monorepo
+scope: globs
+packages:
+  .: [base]
+  services/api: [go, "docker"]  # backend
+  ./web/:
+    - nextjs
+
+    - base
+`)
+	c, err := ParsePackages(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := PackagesConfig{Scope: ScopeGlobs, Packages: []Package{
+		{Path: ".", Rules: []string{"base"}},
+		{Path: "services/api", Rules: []string{"go", "docker"}},
+		{Path: "web", Rules: []string{"nextjs", "base"}},
+	}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+	if again, err := ParsePackages(c.Marshal()); err != nil || !reflect.DeepEqual(again, want) {
+		t.Errorf("Marshal does not round-trip: %+v, %v", again, err)
+	}
+
+	for _, bad := range []string{"scope: everywhere\n", "packages:\n  ../x: [go]\n", "rules: [go]\n", "packages:\n  web: go\n"} {
+		if _, err := ParsePackages([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestInstallRules_Packages(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"gorules.mdc":     "---\ndescription: Go\nglobs:\nalwaysApply: true\n---\nGo\n",
+		"nextjsrules.mdc": "---\ndescription: Next\nglobs: *.tsx\nalwaysApply: false\n---\nNext\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	install := func(dir string, scoped bool, rule string) {
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: []string{rule}, Mode: domain.ModeSymlink, Source: DirSource("test", canon), BaseDir: project,
+			Dir: dir, Scoped: scoped, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install %s: %v", dir, err)
+		}
+	}
+	install("services/api", false, "go")
+	install("web", true, "nextjs")
+
+	nested := filepath.Join(project, "services", "api", ".cursor", "rules", "gorules.mdc")
+	if link, err := os.Readlink(nested); err != nil || link != filepath.Join(canon, "gorules.mdc") {
+		t.Errorf("expected nested symlink, got %q, %v", link, err)
+	}
+	scoped, err := os.ReadFile(filepath.Join(project, ".cursor", "rules", "web-nextjsrules.mdc"))
+	if err != nil || !strings.Contains(string(scoped), "globs: web/**/*.tsx\n") {
+		t.Errorf("expected a scoped copy, got %q, %v", scoped, err)
+	}
+	if e, ok := manifest.Lookup(".cursor/rules/web-nextjsrules.mdc"); !ok || e.Dir != "web" || !e.Scoped || e.Mode != domain.ModeCopy {
+		t.Errorf("unexpected manifest entry: %+v", e)
+	}
+
+	drift, _, err := CheckTargets(context.Background(), CheckOptions{Manifest: manifest})
+	if err != nil || len(drift) != 0 {
+		t.Errorf("fresh package installs reported as drifted: %+v, %v", drift, err)
+	}
+
+	n, err := RemoveInstalls(context.Background(), RemoveOptions{Packages: []string{"services/api"}, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 removed file, got %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(project, "services")); !os.IsNotExist(err) {
+		t.Errorf("expected the package's empty rules directories to be removed, got %v", err)
+	}
+	if len(manifest.Entries) != 1 {
+		t.Errorf("expected the web entry to remain: %+v", manifest.Entries)
+	}
+}
+
+func TestScopedTarget_RejectsSharedFiles(t *testing.T) {
+	claude, _ := LookupTarget("claude")
+	_, err := ScopedTarget{Target: claude, Dir: "web"}.Render([]domain.Rule{{Name: "go", AlwaysApply: true, Body: "Go\n"}}, domain.RenderOptions{})
+	if err == nil {
+		t.Error("expected an error for a root CLAUDE.md shared by every package")
+	}
+}
+
+func TestSyncPackages(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc":   "---\nalwaysApply: true\n---\nBase\n",
+		"gorules.mdc":     "---\nalwaysApply: true\n---\nGo\n",
+		"pythonrules.mdc": "---\nalwaysApply: true\n---\nPy\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	claude, _ := LookupTarget("claude")
+	install := func(target domain.Target, dir string, rules ...string) {
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: rules, Mode: domain.ModeSymlink, Source: DirSource("test", canon), Target: target,
+			BaseDir: project, Dir: dir, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	}
+	install(nil, "", "base")
+	install(nil, "services/api", "go")
+	install(nil, "tools", "python")
+	install(claude, "services/api", "go")
+
+	config, err := ParsePackages([]byte("packages:\n  services/api: [go, python]\n  web: [base]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = SyncPackages(context.Background(), SyncPackagesOptions{Config: config, Source: DirSource("test", canon), Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, e := range manifest.Entries {
+		got = append(got, entryTarget(e)+" "+e.Path)
+	}
+	sort.Strings(got)
+	want := []string{
+		"claude services/api/.claude/rules/go.md",
+		"claude services/api/.claude/rules/python.md",
+		"claude services/api/CLAUDE.md",
+		"claude web/.claude/rules/base.md",
+		"claude web/CLAUDE.md",
+		"cursor .cursor/rules/baserules.mdc",
+		"cursor services/api/.cursor/rules/gorules.mdc",
+		"cursor services/api/.cursor/rules/pythonrules.mdc",
+		"cursor web/.cursor/rules/baserules.mdc",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got entries %v\nwant %v", got, want)
+	}
+	if info, err := os.Lstat(filepath.Join(project, "web", ".cursor", "rules", "baserules.mdc")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("new package not installed in the mode of its target: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, "tools", ".cursor", "rules", "pythonrules.mdc")); !os.IsNotExist(err) {
+		t.Errorf("files of a package no longer listed were kept: %v", err)
+	}
+}
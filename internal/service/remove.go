@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// RemoveOptions configures RemoveInstalls.
+type RemoveOptions struct {
+	Rules    []string // limit to files generated only from these rules; empty removes every file
+	Targets  []string // limit to files installed for these targets; empty means all targets
+	Packages []string // limit to files installed for these monorepo packages ("." is the project); empty means all
+	Force    bool     // remove copies even if they were modified since they were installed
+	Manifest *Manifest
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// RemoveInstalls deletes the files recorded in the manifest and forgets them. Managed files lose
+// only their managed block, and a target's config file edits are undone once none of its files are
+// left. It returns the number of files removed; the caller saves the manifest.
+func RemoveInstalls(ctx context.Context, opts RemoveOptions) (int, error) {
+	removed := 0
+	touched := map[string]ManifestEntry{} // a removed entry per target and package, to find the target again
+	entries := append([]ManifestEntry{}, opts.Manifest.Entries...)
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		target := entryTarget(e)
+		if len(opts.Targets) > 0 && !selected(opts.Targets, target) {
+			continue
+		}
+		if len(opts.Packages) > 0 && !selectedPackage(opts.Packages, e.Dir) {
+			continue
+		}
+		if len(opts.Rules) > 0 && !allSelected(opts.Rules, e.Rules) {
+			if anySelected(opts.Rules, e.Rules) {
+				fmt.Fprintf(opts.Stdout, "[ai-rules-link] Keeping %s: it also holds rules %s.\n", e.Path, strings.Join(e.Rules, ", "))
+			}
+			continue
+		}
+		dst := opts.Manifest.Abs(e)
+		if !opts.Force && modifiedSinceInstall(dst, e) {
+			fmt.Fprintf(opts.Stdout, "[ai-rules-link] Skipping %s: modified since it was installed. Use --force to remove it.\n", e.Path)
+			continue
+		}
+		if err := removeInstalled(dst, e.Managed); err != nil {
+			return removed, err
+		}
+		removeEmptyDirs(filepath.Dir(dst), opts.Manifest.Dir())
+		opts.Manifest.Forget(e.Path)
+		touched[target+"\x00"+e.Dir] = e
+		fmt.Fprintf(opts.Stdout, "Removed %s\n", e.Path)
+		removed++
+	}
+
+	for _, e := range touched {
+		name := entryTarget(e)
+		if hasTargetEntries(opts.Manifest, name, e.Dir) {
+			continue
+		}
+		t, err := lookupEntryTool(e)
+		if err != nil {
+			continue
+		}
+		if c, ok := t.(domain.ConfiguredTarget); ok {
+			changed, err := c.Unconfigure(opts.Manifest.Base(e))
+			if err != nil {
+				return removed, fmt.Errorf("unconfigure %s: %w", name, err)
+			}
+			if changed {
+				fmt.Fprintf(opts.Stdout, "Removed the %s configuration for its rules\n", name)
+			}
+		}
+	}
+	return removed, nil
+}
+
+// modifiedSinceInstall reports whether a written file no longer has the content recorded for it.
+func modifiedSinceInstall(dst string, e ManifestEntry) bool {
+	if e.SHA256 == "" {
+		return false
+	}
+	current, err := installedContent(dst, e.Managed)
+	return err == nil && ContentHash(current) != e.SHA256
+}
+
+// removeInstalled deletes dst, or only its managed block when managed. A managed file with nothing
+// else in it is deleted too.
+func removeInstalled(dst string, managed bool) error {
+	if managed {
+		data, err := os.ReadFile(dst)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", dst, err)
+		}
+		rest := utils.RemoveManagedBlock(data)
+		if len(bytes.TrimSpace(rest)) > 0 {
+			if err := os.WriteFile(dst, rest, 0644); err != nil {
+				return fmt.Errorf("write %s: %w", dst, err)
+			}
+			return nil
+		}
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %s: %w", dst, err)
+	}
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty, stopping at root.
+func removeEmptyDirs(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func hasTargetEntries(m *Manifest, target, dir string) bool {
+	for _, e := range m.Entries {
+		if entryTarget(e) == target && e.Dir == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedPackage reports whether dir, a ManifestEntry.Dir, is one of the package paths in filter.
+func selectedPackage(filter []string, dir string) bool {
+	for _, p := range filter {
+		if PackageDir(p) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// allSelected reports whether every rule, a name or a section selection, is named in filter.
+func allSelected(filter, rules []string) bool {
+	for _, r := range rules {
+		if name, _ := domain.ParseRuleSpec(r); !selected(filter, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func anySelected(filter, rules []string) bool {
+	for _, r := range rules {
+		if name, _ := domain.ParseRuleSpec(r); selected(filter, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestRemoveInstalls_KeepsHandWrittenText(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"baserules.mdc": "---\nalwaysApply: true\n---\nBase\n", "gorules.mdc": "go"})
+	project := t.TempDir()
+	os.WriteFile(filepath.Join(project, "CLAUDE.md"), []byte("# Notes\n"), 0644)
+	manifest, _ := LoadManifest(project)
+	claude, _ := LookupTarget("claude")
+	for _, target := range []domain.Target{claude, nil} {
+		err := InstallRules(context.Background(), InstallOptions{
+			Rules: []string{"base", "go"}, Mode: domain.ModeCopy, Source: DirSource("test", canon),
+			Target: target, BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	}
+
+	n, err := RemoveInstalls(context.Background(), RemoveOptions{Targets: []string{"claude"}, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 removed files, got %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(project, "CLAUDE.md")); string(got) != "# Notes\n" {
+		t.Errorf("hand-written text not kept: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".claude")); !os.IsNotExist(err) {
+		t.Errorf("expected empty .claude directory to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); err != nil {
+		t.Errorf("cursor files should be untouched: %v", err)
+	}
+}
+
+func TestRemoveInstalls_SkipsModifiedCopies(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	project := t.TempDir()
+	manifest := installForSync(t, canon, project, domain.ModeCopy, "go")
+	dst := filepath.Join(project, ".cursor", "rules", "gorules.mdc")
+	os.WriteFile(dst, []byte("edited"), 0644)
+
+	n, err := RemoveInstalls(context.Background(), RemoveOptions{Rules: []string{"go"}, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil || n != 0 {
+		t.Fatalf("expected nothing removed, got %d, %v", n, err)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Errorf("modified copy was removed: %v", err)
+	}
+}
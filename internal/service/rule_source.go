@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// RuleSource is a location rule files are read from: a directory on disk or the embedded rule set.
+type RuleSource struct {
+	// Name is a human readable label such as "~/ai-rules" or "embedded".
+	Name string
+	// Dir is the directory on disk holding the rules. It is empty for the embedded source.
+	Dir string
+	// FS reads rule files by their bare filename (e.g. "gorules.mdc").
+	FS fs.FS
+}
+
+// DirSource returns a RuleSource backed by a directory on disk.
+func DirSource(name, dir string) RuleSource {
+	return RuleSource{Name: name, Dir: dir, FS: os.DirFS(dir)}
+}
+
+// EmbeddedSource returns a RuleSource for the rules compiled into the binary.
+// The embedded FS is expected to hold the rules under a top-level "rules/" directory.
+func EmbeddedSource(embedded fs.FS) RuleSource {
+	sub, err := fs.Sub(embedded, "rules")
+	if err != nil {
+		sub = embedded
+	}
+	return RuleSource{Name: "embedded", FS: sub}
+}
+
+// Embedded reports whether the source has no on-disk directory to link to.
+func (s RuleSource) Embedded() bool {
+	return s.Dir == ""
+}
+
+// Path returns the on-disk path of a rule, or "" for the embedded source.
+func (s RuleSource) Path(rule string) string {
+	if s.Embedded() {
+		return ""
+	}
+	return filepath.Join(s.Dir, RuleFilename(rule))
+}
+
+// ReadRule returns the raw content of a rule.
+func (s RuleSource) ReadRule(rule string) ([]byte, error) {
+	return fs.ReadFile(s.FS, RuleFilename(rule))
+}
+
+// LoadRule reads and parses a rule.
+func (s RuleSource) LoadRule(rule string) (domain.Rule, error) {
+	raw, err := s.ReadRule(rule)
+	if err != nil {
+		return domain.Rule{}, err
+	}
+	r, err := domain.ParseRule(strings.ToLower(rule), raw)
+	if err != nil {
+		return r, err
+	}
+	r.SourcePath = s.Path(rule)
+	return r, nil
+}
+
+// LoadRuleSpec loads a rule selected as "name" or "name#section...", cut down to the named
+// sections; see domain.SelectSections.
+func (s RuleSource) LoadRuleSpec(spec string) (domain.Rule, error) {
+	name, sections := domain.ParseRuleSpec(spec)
+	r, err := s.LoadRule(name)
+	if err != nil || len(sections) == 0 {
+		return r, err
+	}
+	return domain.SelectSections(r, sections)
+}
+
+// HasRule reports whether the source contains the given rule.
+func (s RuleSource) HasRule(rule string) bool {
+	_, err := fs.Stat(s.FS, RuleFilename(rule))
+	return err == nil
+}
+
+// ListRules returns the names of all rules in the source, sorted.
+func (s RuleSource) ListRules() ([]string, error) {
+	entries, err := fs.ReadDir(s.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	var rules []string
+	for _, entry := range entries {
+		if name, ok := RuleName(entry.Name()); ok && !entry.IsDir() {
+			rules = append(rules, name)
+		}
+	}
+	return rules, nil
+}
+
+// RuleFilename maps a rule name as passed to --rule to its file name, e.g. "go" -> "gorules.mdc".
+func RuleFilename(rule string) string {
+	return strings.ToLower(rule) + "rules.mdc"
+}
+
+// RuleName maps a rule file name back to its rule name, e.g. "gorules.mdc" -> "go".
+func RuleName(filename string) (string, bool) {
+	if !strings.HasSuffix(filename, "rules.mdc") || filename == ConsolidatedFilename {
+		return "", false
+	}
+	name := strings.TrimSuffix(filename, "rules.mdc")
+	return name, name != ""
+}
+
+// RuleNameOf returns the rule name for a rule file path, e.g. "/x/gorules.mdc" -> "go".
+func RuleNameOf(path string) string {
+	name, _ := RuleName(filepath.Base(path))
+	return name
+}
+
+// ProjectRulesDir is the per-project rules directory, checked before any user-level location.
+const ProjectRulesDir = ".ai-rules"
+
+// CandidateSources returns every location rules may be read from, in lookup order, whether or not
+// it exists: the project's .ai-rules/, $XDG_CONFIG_HOME/ai-rules, ~/ai-rules and the embedded rules.
+func CandidateSources(projectDir string, embedded fs.FS) []RuleSource {
+	var sources []RuleSource
+	if projectDir != "" {
+		sources = append(sources, DirSource(ProjectRulesDir, filepath.Join(projectDir, ProjectRulesDir)))
+	}
+	sources = append(sources, userSources()...)
+	return append(sources, EmbeddedSource(embedded))
+}
+
+// userSources returns the user-level rules directories, $XDG_CONFIG_HOME/ai-rules and ~/ai-rules,
+// named as the manifest records them.
+func userSources() []RuleSource {
+	var sources []RuleSource
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		sources = append(sources, DirSource("$XDG_CONFIG_HOME/ai-rules", filepath.Join(xdg, "ai-rules")))
+	}
+	home, _ := os.UserHomeDir()
+	return append(sources, DirSource("~/ai-rules", filepath.Join(home, "ai-rules")))
+}
+
+// ResolveRuleSource returns the first existing rules directory in lookup order, falling back to
+// the embedded rules. Falling back is reported on warn together with the directories checked.
+func ResolveRuleSource(projectDir string, embedded fs.FS, warn io.Writer) RuleSource {
+	sources := CandidateSources(projectDir, embedded)
+	src := sources[resolvedIndex(sources)]
+	if src.Embedded() {
+		var checked []string
+		for _, s := range sources {
+			if !s.Embedded() && s.Name != ProjectRulesDir {
+				checked = append(checked, fmt.Sprintf("%s (%s)", s.Name, s.Dir))
+			}
+		}
+		fmt.Fprintf(warn, "[ai-rules-link] No rules directory in %s; using the embedded rules. Run 'ai-rules-link which <rule>' to see where rules are looked up.\n", strings.Join(checked, ", "))
+	}
+	return src
+}
+
+// resolvedIndex returns the index of the source ResolveRuleSource picks: the first existing
+// directory, or else the embedded rules, which CandidateSources always lists last.
+func resolvedIndex(sources []RuleSource) int {
+	for i, src := range sources {
+		if !src.Embedded() && isDir(src.Dir) {
+			return i
+		}
+	}
+	return len(sources) - 1
+}
+
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
+}
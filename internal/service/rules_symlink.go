@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,11 +13,13 @@ type SymlinkOptions struct {
 	Rules         []string
 	CanonicalDir  string
 	DestRulesPath string
-	Stdout        *os.File // for testability, or use io.Writer
-	Stderr        *os.File
+	Relative      bool // link with a path relative to DestRulesPath instead of an absolute one
+	Stdout        io.Writer
+	Stderr        io.Writer
 }
 
 // SymlinkRules creates symlinks for the specified rules from CanonicalDir to DestRulesPath.
+// When Relative is set, link targets are relative so they stay valid in another checkout.
 func SymlinkRules(ctx context.Context, opts SymlinkOptions) error {
 	if len(opts.Rules) == 0 {
 		fmt.Fprintln(opts.Stderr, "No rules specified. Use --rule for each rule you want to symlink (e.g., --rule=go --rule=base)")
@@ -34,16 +37,25 @@ func SymlinkRules(ctx context.Context, opts SymlinkOptions) error {
 			fmt.Fprintf(opts.Stderr, "Canonical rules file does not exist for '%s': %s\n", flag, src)
 			continue
 		}
+		linkTarget := src
+		if opts.Relative {
+			rel, err := relativeLink(opts.DestRulesPath, src)
+			if err != nil {
+				fmt.Fprintf(opts.Stderr, "Failed to compute relative path for %s: %v\n", filename, err)
+				continue
+			}
+			linkTarget = rel
+		}
 		info, err := os.Lstat(dst)
 		if err == nil && info.Mode()&os.ModeSymlink != 0 {
 			target, err := os.Readlink(dst)
-			if err == nil && target == src {
+			if err == nil && target == linkTarget {
 				fmt.Fprintf(opts.Stdout, "Symlink for %s already exists and is correct.\n", filename)
 				continue
 			}
 		}
 		os.Remove(dst)
-		if err := os.Symlink(src, dst); err != nil {
+		if err := os.Symlink(linkTarget, dst); err != nil {
 			fmt.Fprintf(opts.Stderr, "Failed to create symlink for %s: %v\n", filename, err)
 		} else {
 			fmt.Fprintf(opts.Stdout, "Symlinked %s into %s\n", filename, opts.DestRulesPath)
@@ -51,3 +63,16 @@ func SymlinkRules(ctx context.Context, opts SymlinkOptions) error {
 	}
 	return nil
 }
+
+// relativeLink returns the path of target relative to dir, resolving both to absolute paths first.
+func relativeLink(dir, target string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(absDir, absTarget)
+}
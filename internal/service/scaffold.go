@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"ai-rules-link/internal/domain"
+)
+
+// NewRuleOptions configures ScaffoldRule.
+type NewRuleOptions struct {
+	Name        string // rule name as passed to --rule, e.g. "docker"
+	Description string // defaults to "<Name> specific set of rules"
+	Globs       []string
+	AlwaysApply bool
+	Dir         string       // writable rules directory the file is created in
+	Sources     []RuleSource // sources in lookup order, checked so a new rule does not silently shadow or get shadowed by an existing one
+	Force       bool         // overwrite or shadow an existing rule of the same name
+}
+
+var ruleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+var ruleTemplate = template.Must(template.New("rule").Parse(`---
+description: {{.Description}}
+globs:{{if .Globs}} {{.Globs}}{{end}}
+alwaysApply: {{.AlwaysApply}}
+---
+
+**{{.Title}}-Specific Instructions:**
+
+1.  **First Directive:** Describe what the assistant must always do.
+    Why: Explain the reason, so the directive can be applied with judgment.
+2.  **Second Directive:** Describe the next convention to follow.
+    Why: Explain the reason.
+    Example:
+      Show a short example when it makes the directive clearer.
+`))
+
+// ScaffoldRule creates <name>rules.mdc in opts.Dir with frontmatter in the style of the embedded
+// rules and a skeleton of numbered directives. It returns the path of the new file.
+func ScaffoldRule(ctx context.Context, opts NewRuleOptions) (string, error) {
+	name := strings.ToLower(opts.Name)
+	if !ruleNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid rule name %q: use lowercase letters, digits and dashes", opts.Name)
+	}
+	if strings.HasSuffix(name, "rules") {
+		return "", fmt.Errorf("invalid rule name %q: leave off the \"rules\" suffix, it is added to the file name", opts.Name)
+	}
+	path := filepath.Join(opts.Dir, RuleFilename(name))
+	if _, err := os.Stat(path); err == nil && !opts.Force {
+		return "", fmt.Errorf("%s already exists; use --force to overwrite it", path)
+	}
+	own := -1 // lookup position of opts.Dir
+	for i, src := range opts.Sources {
+		if !src.Embedded() && filepath.Clean(src.Dir) == filepath.Clean(opts.Dir) {
+			own = i
+		}
+	}
+	for i, src := range opts.Sources {
+		if i == own || !src.HasRule(name) {
+			continue
+		}
+		switch {
+		case own >= 0 && i < own:
+			// --force cannot help: the existing rule is found first, so the new one would never be used.
+			return "", fmt.Errorf("rule %q already exists in %s rules, which are read before %s, so the new rule would never be used; edit %s instead", name, src.Name, opts.Dir, src.Path(name))
+		case opts.Force:
+		case own >= 0:
+			return "", fmt.Errorf("rule %q already exists in %s rules; the new rule in %s would take its place. Use --force to shadow it", name, src.Name, opts.Dir)
+		default:
+			return "", fmt.Errorf("rule %q already exists in %s rules; use --force to create another one", name, src.Name)
+		}
+	}
+
+	description := opts.Description
+	if description == "" {
+		description = title(name) + " specific set of rules"
+	}
+	var buf bytes.Buffer
+	err := ruleTemplate.Execute(&buf, map[string]any{
+		"Description": domain.FrontmatterValue(description),
+		"Globs":       domain.JoinGlobs(opts.Globs),
+		"AlwaysApply": opts.AlwaysApply,
+		"Title":       title(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("render rule: %w", err)
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return "", fmt.Errorf("create %s: %w", opts.Dir, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("write rule: %w", err)
+	}
+	return path, nil
+}
+
+// title turns a rule name into a heading, e.g. "react-native" -> "React Native".
+func title(name string) string {
+	words := strings.Split(name, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
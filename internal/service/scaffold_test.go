@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScaffoldRule_WritesFrontmatterAndSkeleton(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".ai-rules")
+	path, err := ScaffoldRule(context.Background(), NewRuleOptions{
+		Name:  "docker",
+		Globs: []string{"**/Dockerfile", "**/*.dockerfile"},
+		Dir:   dir,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join(dir, "dockerrules.mdc") {
+		t.Errorf("unexpected path: %s", path)
+	}
+	content, _ := os.ReadFile(path)
+	want := "---\ndescription: Docker specific set of rules\nglobs: **/Dockerfile,**/*.dockerfile\nalwaysApply: false\n---\n"
+	if !strings.HasPrefix(string(content), want) {
+		t.Errorf("unexpected frontmatter:\n%s", content)
+	}
+	if !strings.Contains(string(content), "1.  **First Directive:**") || !strings.Contains(string(content), "    Why: ") {
+		t.Errorf("missing directive skeleton:\n%s", content)
+	}
+}
+
+func TestScaffoldRule_EmptyGlobsMatchHouseStyle(t *testing.T) {
+	dir := t.TempDir()
+	path, err := ScaffoldRule(context.Background(), NewRuleOptions{Name: "rust", AlwaysApply: true, Description: "Rust language specific set of rules", Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "---\ndescription: Rust language specific set of rules\nglobs:\nalwaysApply: true\n---\n") {
+		t.Errorf("unexpected frontmatter:\n%s", content)
+	}
+}
+
+func TestScaffoldRule_RefusesToShadow(t *testing.T) {
+	embedded := EmbeddedSource(fstest.MapFS{"rules/gorules.mdc": {Data: []byte("go")}})
+	dir := t.TempDir()
+	opts := NewRuleOptions{Name: "go", Dir: dir, Sources: []RuleSource{DirSource("~/ai-rules", dir), embedded}}
+	if _, err := ScaffoldRule(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "shadow") {
+		t.Fatalf("expected shadowing error, got: %v", err)
+	}
+	opts.Force = true
+	if _, err := ScaffoldRule(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error with force: %v", err)
+	}
+	opts.Force = false
+	if _, err := ScaffoldRule(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected existing file error, got: %v", err)
+	}
+}
+
+func TestScaffoldRule_RefusesToBeShadowed(t *testing.T) {
+	project := newCanonicalDir(t, map[string]string{"gorules.mdc": "go"})
+	dir := t.TempDir()
+	opts := NewRuleOptions{Name: "go", Dir: dir, Force: true, Sources: []RuleSource{DirSource(ProjectRulesDir, project), DirSource("~/ai-rules", dir)}}
+	_, err := ScaffoldRule(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "read before") || !strings.Contains(err.Error(), filepath.Join(project, "gorules.mdc")) {
+		t.Fatalf("expected an error naming the rule that wins, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gorules.mdc")); !os.IsNotExist(err) {
+		t.Error("a rule that would never be used was created")
+	}
+}
+
+func TestScaffoldRule_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "Go Lang", "../escape", "gorules"} {
+		if _, err := ScaffoldRule(context.Background(), NewRuleOptions{Name: name, Dir: t.TempDir()}); err == nil {
+			t.Errorf("expected error for name %q", name)
+		}
+	}
+}
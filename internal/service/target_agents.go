@@ -0,0 +1,39 @@
+package service
+
+import (
+	"ai-rules-link/internal/domain"
+)
+
+// AgentsTarget maintains managed blocks in AGENTS.md files. Rules whose globs all fall under one
+// subdirectory go into that directory's AGENTS.md, since agents read the nearest AGENTS.md for the
+// files they work on; everything else goes into the root AGENTS.md.
+type AgentsTarget struct {
+	// File is the file name written in each directory, normally "AGENTS.md".
+	File string
+	// Dir is the directory of the root file relative to the base dir; empty for the base dir itself.
+	Dir string
+	// Flat puts every rule in the root file.
+	Flat bool
+}
+
+func init() {
+	RegisterTarget(AgentsTarget{File: "AGENTS.md"})
+}
+
+// Name implements domain.Target.
+func (t AgentsTarget) Name() string { return "agents" }
+
+// Layout implements domain.Target.
+func (t AgentsTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{SingleFile: true}
+}
+
+// Render implements domain.Target. With Consolidate every rule goes into the root file.
+func (t AgentsTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	return nestedContextFiles(rules, t.Dir, t.File, t.Flat || opts.Consolidate), nil
+}
+
+// Global implements domain.GlobalTarget: Codex reads personal guidance from ~/.codex/AGENTS.md.
+func (t AgentsTarget) Global() domain.Target {
+	return AgentsTarget{File: "AGENTS.md", Dir: ".codex", Flat: true}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+func TestAgentsTarget_NestsDirectoryScopedRules(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc":   "---\nalwaysApply: true\n---\nBase body\n",
+		"gorules.mdc":     "---\nglobs: **/*.go\nalwaysApply: false\n---\nGo body\n",
+		"nextjsrules.mdc": "---\nglobs: web/**/*.tsx,web/**/*.ts\nalwaysApply: false\n---\nNext body\n",
+	})
+	base := t.TempDir()
+	os.WriteFile(filepath.Join(base, "AGENTS.md"), []byte("# Agents\n\nBuild with make.\n"), 0644)
+	target, _ := LookupTarget("agents")
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:   []string{"base", "go", "nextjs"},
+		Mode:    domain.ModeCopy,
+		Source:  DirSource("test", canon),
+		Target:  target,
+		BaseDir: base,
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root, _ := os.ReadFile(filepath.Join(base, "AGENTS.md"))
+	if !strings.HasPrefix(string(root), "# Agents\n\nBuild with make.\n\n") {
+		t.Errorf("existing prose not preserved:\n%s", root)
+	}
+	block, _ := utils.ExtractManagedBlock(root)
+	if string(block) != "Base body\n\n> Applies to files matching `**/*.go`.\n\nGo body\n" {
+		t.Errorf("unexpected root block: %q", block)
+	}
+	web, err := os.ReadFile(filepath.Join(base, "web", "AGENTS.md"))
+	if err != nil {
+		t.Fatalf("expected nested AGENTS.md: %v", err)
+	}
+	block, _ = utils.ExtractManagedBlock(web)
+	if !strings.Contains(string(block), "Next body") || strings.Contains(string(block), "Go body") {
+		t.Errorf("unexpected nested block: %q", block)
+	}
+}
+
+func TestAgentsTarget_ConsolidateUsesRootOnly(t *testing.T) {
+	rules := []domain.Rule{{Name: "nextjs", Globs: []string{"web/**/*.tsx"}, Body: "Next\n"}}
+	files, err := AgentsTarget{File: "AGENTS.md"}.Render(rules, domain.RenderOptions{Consolidate: true})
+	if err != nil || len(files) != 1 || files[0].Path != "AGENTS.md" {
+		t.Errorf("unexpected files: %+v, %v", files, err)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+// AiderTarget writes rules into a managed block of Aider's conventions file and adds that file to
+// the read list in .aider.conf.yml, so Aider loads it on every run.
+type AiderTarget struct {
+	// File is the conventions file, normally "CONVENTIONS.md".
+	File string
+	// Config is Aider's config file, normally ".aider.conf.yml".
+	Config string
+	// Absolute lists File in the read list by absolute path, for a config that applies in any directory.
+	Absolute bool
+}
+
+func init() {
+	RegisterTarget(AiderTarget{File: "CONVENTIONS.md", Config: ".aider.conf.yml"})
+}
+
+// Name implements domain.Target.
+func (t AiderTarget) Name() string { return "aider" }
+
+// Layout implements domain.Target.
+func (t AiderTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{SingleFile: true}
+}
+
+// Render implements domain.Target. Aider has a single conventions file, so Consolidate changes nothing.
+func (t AiderTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	return []domain.TargetFile{{Path: t.File, Content: joinMarkdown(rules), Rules: ruleNames(rules), Managed: true}}, nil
+}
+
+// Global implements domain.GlobalTarget: Aider reads ~/.aider.conf.yml in every project, so the
+// conventions file is kept under ~/.aider and listed by absolute path.
+func (t AiderTarget) Global() domain.Target {
+	return AiderTarget{File: filepath.Join(".aider", "CONVENTIONS.md"), Config: ".aider.conf.yml", Absolute: true}
+}
+
+// Configure implements domain.ConfiguredTarget by adding File to the read list in Config.
+func (t AiderTarget) Configure(baseDir string) (bool, error) {
+	return t.editConfig(baseDir, utils.AddYAMLListItem)
+}
+
+// Unconfigure implements domain.ConfiguredTarget by taking File out of the read list again. A
+// config file left empty is removed.
+func (t AiderTarget) Unconfigure(baseDir string) (bool, error) {
+	return t.editConfig(baseDir, utils.RemoveYAMLListItem)
+}
+
+func (t AiderTarget) editConfig(baseDir string, edit func([]byte, string, string) ([]byte, bool, error)) (bool, error) {
+	path := filepath.Join(baseDir, t.Config)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("read %s: %w", path, err)
+	}
+	read := filepath.ToSlash(t.File)
+	if t.Absolute {
+		read = filepath.Join(baseDir, t.File)
+	}
+	updated, changed, err := edit(data, "read", read)
+	if err != nil {
+		return false, fmt.Errorf("edit %s: %w", path, err)
+	}
+	if !changed {
+		return false, nil
+	}
+	if len(bytes.TrimSpace(updated)) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("remove %s: %w", path, err)
+		}
+		return true, nil
+	}
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		return false, fmt.Errorf("write %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestAiderTarget_InstallAndRemove(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "---\nglobs: *.go\nalwaysApply: false\n---\nGo body\n"})
+	base := t.TempDir()
+	config := "# Aider settings\nmodel: sonnet # default model\nread:\n  - docs/ARCHITECTURE.md\n"
+	os.WriteFile(filepath.Join(base, ".aider.conf.yml"), []byte(config), 0644)
+	manifest, _ := LoadManifest(base)
+	opts := InstallOptions{
+		Rules:    []string{"go"},
+		Mode:     domain.ModeCopy,
+		Source:   DirSource("test", canon),
+		Target:   AiderTarget{File: "CONVENTIONS.md", Config: ".aider.conf.yml"},
+		BaseDir:  base,
+		Manifest: manifest,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	}
+	for i := 0; i < 2; i++ {
+		if err := InstallRules(context.Background(), opts); err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	}
+	got, _ := os.ReadFile(filepath.Join(base, ".aider.conf.yml"))
+	if want := config + "  - CONVENTIONS.md\n"; string(got) != want {
+		t.Errorf("unexpected config:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(base, "CONVENTIONS.md")); err != nil {
+		t.Fatalf("expected CONVENTIONS.md: %v", err)
+	}
+
+	n, err := RemoveInstalls(context.Background(), RemoveOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil || n != 1 {
+		t.Fatalf("remove: %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(base, ".aider.conf.yml")); string(got) != config {
+		t.Errorf("config not restored:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(base, "CONVENTIONS.md")); !os.IsNotExist(err) {
+		t.Errorf("expected CONVENTIONS.md to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// ClaudeTarget maintains a managed block in CLAUDE.md for Claude-style tools. The block imports one
+// rendered markdown file per rule with @path references, or inlines every rule when consolidating.
+type ClaudeTarget struct {
+	// File is the instructions file relative to the base dir, normally "CLAUDE.md".
+	File string
+	// RulesDir holds the rendered rule files, relative to the base dir.
+	RulesDir string
+}
+
+func init() {
+	RegisterTarget(ClaudeTarget{File: "CLAUDE.md", RulesDir: filepath.Join(".claude", "rules")})
+}
+
+// Name implements domain.Target.
+func (t ClaudeTarget) Name() string { return "claude" }
+
+// Layout implements domain.Target.
+func (t ClaudeTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: t.RulesDir}
+}
+
+// Render implements domain.Target.
+func (t ClaudeTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	if opts.Consolidate {
+		return []domain.TargetFile{{Path: t.File, Content: joinMarkdown(rules), Rules: ruleNames(rules), Managed: true}}, nil
+	}
+	var files []domain.TargetFile
+	var imports strings.Builder
+	for _, r := range rules {
+		path := filepath.Join(t.RulesDir, r.Name+".md")
+		files = append(files, domain.TargetFile{Path: path, Content: []byte(renderMarkdown(r)), Rules: []string{r.Name}})
+		rel, err := filepath.Rel(filepath.Dir(t.File), path)
+		if err != nil {
+			return nil, err
+		}
+		imports.WriteString("@" + filepath.ToSlash(rel) + "\n")
+	}
+	files = append(files, domain.TargetFile{Path: t.File, Content: []byte(imports.String()), Rules: ruleNames(rules), Managed: true})
+	return files, nil
+}
+
+// Global implements domain.GlobalTarget: Claude Code reads user instructions from ~/.claude/CLAUDE.md.
+func (t ClaudeTarget) Global() domain.Target {
+	return ClaudeTarget{File: filepath.Join(".claude", "CLAUDE.md"), RulesDir: filepath.Join(".claude", "rules")}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+func TestClaudeTarget_ImportsRenderedRules(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc": "---\ndescription: Base\nglobs:\nalwaysApply: true\n---\n\nBase body\n",
+		"gorules.mdc":   "---\ndescription: Go\nglobs: **/*.go\nalwaysApply: false\n---\n\nGo body\n",
+	})
+	base := t.TempDir()
+	claudeMD := filepath.Join(base, "CLAUDE.md")
+	os.WriteFile(claudeMD, []byte("# My project\n\nKeep this.\n"), 0644)
+	target, _ := LookupTarget("claude")
+
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:   []string{"base", "go"},
+		Mode:    domain.ModeSymlink,
+		Source:  DirSource("test", canon),
+		Target:  target,
+		BaseDir: base,
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(claudeMD)
+	if !strings.HasPrefix(string(content), "# My project\n\nKeep this.\n\n") {
+		t.Errorf("hand-written content not preserved:\n%s", content)
+	}
+	block, ok := utils.ExtractManagedBlock(content)
+	if !ok || string(block) != "@.claude/rules/base.md\n@.claude/rules/go.md\n" {
+		t.Errorf("unexpected managed block: %q", block)
+	}
+	goMD, _ := os.ReadFile(filepath.Join(base, ".claude", "rules", "go.md"))
+	if string(goMD) != "> Applies to files matching `**/*.go`.\n\nGo body\n" {
+		t.Errorf("unexpected rendered rule: %q", goMD)
+	}
+	baseMD, _ := os.ReadFile(filepath.Join(base, ".claude", "rules", "base.md"))
+	if string(baseMD) != "Base body\n" {
+		t.Errorf("unexpected rendered rule: %q", baseMD)
+	}
+}
+
+func TestClaudeTarget_ConsolidateInlinesRules(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "base", AlwaysApply: true, Body: "Base body\n"},
+		{Name: "python", Globs: []string{"**/*.py"}, Body: "Python body\n"},
+	}
+	files, err := ClaudeTarget{File: "CLAUDE.md", RulesDir: ".claude/rules"}.Render(rules, domain.RenderOptions{Consolidate: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || !files[0].Managed || files[0].Path != "CLAUDE.md" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	want := "Base body\n\n> Applies to files matching `**/*.py`.\n\nPython body\n"
+	if string(files[0].Content) != want {
+		t.Errorf("unexpected content:\n%q\nwant:\n%q", files[0].Content, want)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// ClineTarget writes rules into Cline's .clinerules directory. Cline applies every rule unless it
+// lists paths, so glob rules get a paths list and other scoped rules keep their scope as a note.
+type ClineTarget struct {
+	// Dir is the rules directory relative to the base dir, normally ".clinerules".
+	Dir string
+}
+
+func init() {
+	RegisterTarget(ClineTarget{Dir: ".clinerules"})
+}
+
+// Name implements domain.Target.
+func (t ClineTarget) Name() string { return "cline" }
+
+// Layout implements domain.Target.
+func (t ClineTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: t.Dir, Frontmatter: []string{"paths"}}
+}
+
+// Render implements domain.Target. A rule without frontmatter is already a valid Cline rule, so
+// it can be linked to its source.
+func (t ClineTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	if opts.Consolidate {
+		return []domain.TargetFile{{
+			Path:    filepath.Join(t.Dir, consolidatedMarkdown),
+			Content: joinMarkdown(rules),
+			Rules:   ruleNames(rules),
+		}}, nil
+	}
+	files := make([]domain.TargetFile, 0, len(rules))
+	for _, r := range rules {
+		content := []byte(clineRule(r))
+		files = append(files, domain.TargetFile{
+			Path:       filepath.Join(t.Dir, r.Name+".md"),
+			Content:    content,
+			Rules:      []string{r.Name},
+			LinkSource: linkSource(r, content),
+		})
+	}
+	return files, nil
+}
+
+// clineRule renders a rule for Cline: glob rules become conditional rules with a paths list.
+func clineRule(r domain.Rule) string {
+	if r.Activation() != domain.ActivationGlob {
+		return renderMarkdown(r)
+	}
+	var sb strings.Builder
+	sb.WriteString("---\npaths:\n")
+	for _, g := range ApplyToGlobs(r.Globs) {
+		fmt.Fprintf(&sb, "  - %q\n", g)
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(r.Body)
+	if !strings.HasSuffix(r.Body, "\n") {
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Global implements domain.GlobalTarget: Cline reads global rules from ~/Documents/Cline/Rules.
+func (t ClineTarget) Global() domain.Target {
+	return ClineTarget{Dir: filepath.Join("Documents", "Cline", "Rules")}
+}
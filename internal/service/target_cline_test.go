@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestClineTarget_Render(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "go", Globs: []string{"*.go"}, Body: "Go\n"},
+		{Name: "docs", Description: "Writing docs", Body: "Docs\n"},
+	}
+	files, err := ClineTarget{Dir: ".clinerules"}.Render(rules, domain.RenderOptions{})
+	if err != nil || len(files) != 2 {
+		t.Fatalf("unexpected result: %+v, %v", files, err)
+	}
+	if want := "---\npaths:\n  - \"**/*.go\"\n---\n\nGo\n"; string(files[0].Content) != want {
+		t.Errorf("go rule:\n%q\nwant:\n%q", files[0].Content, want)
+	}
+	if want := "> Apply when relevant: Writing docs.\n\nDocs\n"; string(files[1].Content) != want {
+		t.Errorf("docs rule:\n%q\nwant:\n%q", files[1].Content, want)
+	}
+}
+
+func TestClineTarget_SymlinksPlainMarkdownRules(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc": "Base body\n",
+		"gorules.mdc":   "---\nglobs: *.go\nalwaysApply: false\n---\nGo body\n",
+	})
+	base := t.TempDir()
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:   []string{"base", "go"},
+		Mode:    domain.ModeSymlink,
+		Source:  DirSource("test", canon),
+		Target:  ClineTarget{Dir: ".clinerules"},
+		BaseDir: base,
+		Stdout:  io.Discard,
+		Stderr:  io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(base, ".clinerules", "base.md")); err != nil || target != filepath.Join(canon, "baserules.mdc") {
+		t.Errorf("expected base.md to link to its source, got %q, %v", target, err)
+	}
+	if _, err := os.Readlink(filepath.Join(base, ".clinerules", "go.md")); err == nil {
+		t.Error("translated go.md should be a copy, not a link")
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// ContinueTarget writes rules into Continue's .continue/rules directory with Continue's name,
+// globs, description and alwaysApply frontmatter.
+type ContinueTarget struct {
+	// Dir is the rules directory relative to the base dir, normally ".continue/rules".
+	Dir string
+}
+
+func init() {
+	RegisterTarget(ContinueTarget{Dir: ".continue/rules"})
+}
+
+// Name implements domain.Target.
+func (t ContinueTarget) Name() string { return "continue" }
+
+// Layout implements domain.Target.
+func (t ContinueTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: t.Dir, Frontmatter: []string{"name", "globs", "description", "alwaysApply"}}
+}
+
+// Render implements domain.Target. Consolidate writes one always-applied file with each rule's
+// scope as a note.
+func (t ContinueTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	if opts.Consolidate {
+		content := "---\nname: consolidated\nalwaysApply: true\n---\n\n" + string(joinMarkdown(rules))
+		return []domain.TargetFile{{
+			Path:    filepath.Join(t.Dir, consolidatedMarkdown),
+			Content: []byte(content),
+			Rules:   ruleNames(rules),
+		}}, nil
+	}
+	files := make([]domain.TargetFile, 0, len(rules))
+	for _, r := range rules {
+		files = append(files, domain.TargetFile{
+			Path:    filepath.Join(t.Dir, r.Name+".md"),
+			Content: []byte(continueRule(r)),
+			Rules:   []string{r.Name},
+		})
+	}
+	return files, nil
+}
+
+// continueRule renders a rule with Continue's frontmatter. Continue attaches a rule that is not
+// always applied when its globs match or, given a description, when the model asks for it.
+func continueRule(r domain.Rule) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "name: %s\n", r.Name)
+	if r.Description != "" {
+		fmt.Fprintf(&sb, "description: %q\n", r.Description)
+	}
+	switch globs := ApplyToGlobs(r.Globs); len(globs) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&sb, "globs: %q\n", globs[0])
+	default:
+		quoted := make([]string, len(globs))
+		for i, g := range globs {
+			quoted[i] = fmt.Sprintf("%q", g)
+		}
+		fmt.Fprintf(&sb, "globs: [%s]\n", strings.Join(quoted, ", "))
+	}
+	fmt.Fprintf(&sb, "alwaysApply: %t\n", r.AlwaysApply)
+	sb.WriteString("---\n\n")
+	sb.WriteString(r.Body)
+	if !strings.HasSuffix(r.Body, "\n") {
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Global implements domain.GlobalTarget: Continue reads user rules from ~/.continue/rules, the same
+// layout as in a project.
+func (t ContinueTarget) Global() domain.Target {
+	return ContinueTarget{Dir: filepath.Join(".continue", "rules")}
+}
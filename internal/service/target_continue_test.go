@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestContinueTarget_Frontmatter(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "base", AlwaysApply: true, Body: "Base\n"},
+		{Name: "go", Description: "Go rules", Globs: []string{"*.go", "cmd/**"}, Body: "Go\n"},
+		{Name: "docker", Globs: []string{"Dockerfile"}, Body: "Docker"},
+	}
+	files, err := ContinueTarget{Dir: ".continue/rules"}.Render(rules, domain.RenderOptions{})
+	if err != nil || len(files) != 3 {
+		t.Fatalf("unexpected result: %+v, %v", files, err)
+	}
+	want := []string{
+		"---\nname: base\nalwaysApply: true\n---\n\nBase\n",
+		"---\nname: go\ndescription: \"Go rules\"\nglobs: [\"**/*.go\", \"cmd/**\"]\nalwaysApply: false\n---\n\nGo\n",
+		"---\nname: docker\nglobs: \"**/Dockerfile\"\nalwaysApply: false\n---\n\nDocker\n",
+	}
+	for i, f := range files {
+		if string(f.Content) != want[i] {
+			t.Errorf("%s:\n%q\nwant:\n%q", f.Path, f.Content, want[i])
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"ai-rules-link/internal/domain"
+)
+
+// CopilotTarget writes GitHub Copilot custom instructions. Always-applied rules go into a managed
+// block of .github/copilot-instructions.md; every other rule gets its own
+// .github/instructions/<name>.instructions.md, with globs translated to Copilot's applyTo.
+type CopilotTarget struct {
+	// Dir is the directory holding Copilot's files, normally ".github".
+	Dir string
+}
+
+func init() {
+	RegisterTarget(CopilotTarget{Dir: ".github"})
+}
+
+// Name implements domain.Target.
+func (t CopilotTarget) Name() string { return "copilot" }
+
+// Layout implements domain.Target.
+func (t CopilotTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: filepath.Join(t.Dir, "instructions"), Frontmatter: []string{"applyTo", "description"}}
+}
+
+// Render implements domain.Target.
+func (t CopilotTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	instructionsFile := filepath.Join(t.Dir, "copilot-instructions.md")
+	if opts.Consolidate {
+		return []domain.TargetFile{{Path: instructionsFile, Content: joinMarkdown(rules), Rules: ruleNames(rules), Managed: true}}, nil
+	}
+	var files []domain.TargetFile
+	var always []domain.Rule
+	for _, r := range rules {
+		if r.AlwaysApply {
+			always = append(always, r)
+			continue
+		}
+		files = append(files, domain.TargetFile{
+			Path:    filepath.Join(t.Dir, "instructions", r.Name+".instructions.md"),
+			Content: []byte(copilotInstructions(r)),
+			Rules:   []string{r.Name},
+		})
+	}
+	if len(always) > 0 {
+		files = append(files, domain.TargetFile{Path: instructionsFile, Content: joinMarkdown(always), Rules: ruleNames(always), Managed: true})
+	}
+	return files, nil
+}
+
+// copilotInstructions renders a scoped rule as a Copilot .instructions.md file.
+func copilotInstructions(r domain.Rule) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	if len(r.Globs) > 0 {
+		fmt.Fprintf(&sb, "applyTo: %q\n", domain.JoinGlobs(ApplyToGlobs(r.Globs)))
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&sb, "description: %q\n", r.Description)
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(r.Body)
+	if !strings.HasSuffix(r.Body, "\n") {
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// ApplyToGlobs translates Cursor globs to workspace-relative globs. Cursor matches a bare pattern
+// such as "*.go" in any directory, so it becomes "**/*.go".
+func ApplyToGlobs(globs []string) []string {
+	out := make([]string, len(globs))
+	for i, g := range globs {
+		g = strings.TrimPrefix(g, "./")
+		if !strings.Contains(g, "/") {
+			g = "**/" + g
+		}
+		out[i] = g
+	}
+	return out
+}
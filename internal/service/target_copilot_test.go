@@ -0,0 +1,45 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestCopilotTarget_SplitsAlwaysAndScopedRules(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "base", AlwaysApply: true, Body: "Base body\n"},
+		{Name: "go", Description: "Go rules", Globs: []string{"*.go", "src/**/*.{go,mod}"}, Body: "Go body\n"},
+	}
+	files, err := CopilotTarget{Dir: ".github"}.Render(rules, domain.RenderOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", files)
+	}
+	scoped := files[0]
+	if scoped.Path != ".github/instructions/go.instructions.md" || scoped.Managed {
+		t.Errorf("unexpected scoped file: %+v", scoped)
+	}
+	want := "---\napplyTo: \"**/*.go,src/**/*.go,src/**/*.mod\"\ndescription: \"Go rules\"\n---\n\nGo body\n"
+	if string(scoped.Content) != want {
+		t.Errorf("unexpected instructions:\n%q\nwant:\n%q", scoped.Content, want)
+	}
+	general := files[1]
+	if general.Path != ".github/copilot-instructions.md" || !general.Managed || string(general.Content) != "Base body\n" {
+		t.Errorf("unexpected general file: %+v", general)
+	}
+	if !reflect.DeepEqual(general.Rules, []string{"base"}) {
+		t.Errorf("unexpected rules: %v", general.Rules)
+	}
+}
+
+func TestApplyToGlobs(t *testing.T) {
+	got := ApplyToGlobs([]string{"*.py", "./web/**/*.tsx", "**/*.md"})
+	want := []string{"**/*.py", "web/**/*.tsx", "**/*.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"path/filepath"
+
+	"ai-rules-link/internal/domain"
+)
+
+// CursorTarget writes .mdc rules into Cursor's project rules directory unchanged.
+type CursorTarget struct {
+	// Dir is the rules directory relative to the base dir, normally ".cursor/rules".
+	Dir string
+}
+
+func init() {
+	RegisterTarget(CursorTarget{Dir: ".cursor/rules"})
+}
+
+// Name implements domain.Target.
+func (t CursorTarget) Name() string { return "cursor" }
+
+// Layout implements domain.Target.
+func (t CursorTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: t.Dir, Frontmatter: []string{"description", "globs", "alwaysApply"}}
+}
+
+// Render implements domain.Target. Cursor reads .mdc files natively, so each rule is written as is
+// and can be linked to its source.
+func (t CursorTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	if opts.Consolidate {
+		return []domain.TargetFile{{
+			Path:    filepath.Join(t.Dir, ConsolidatedFilename),
+			Content: mergeRaw(rules),
+			Rules:   ruleNames(rules),
+		}}, nil
+	}
+	files := make([]domain.TargetFile, 0, len(rules))
+	for _, r := range rules {
+		files = append(files, domain.TargetFile{
+			Path:       filepath.Join(t.Dir, RuleFilename(r.Name)),
+			Content:    r.Raw,
+			Rules:      []string{r.Name},
+			LinkSource: r.SourcePath,
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-rules-link/internal/domain"
+)
+
+// GeminiTarget maintains managed blocks in Gemini CLI context files. Gemini CLI loads every context
+// file from the project root down to the working directory, so directory-scoped glob rules are
+// written next to the files they cover, as for AGENTS.md.
+type GeminiTarget struct {
+	// File is the context file name used when settings do not override it, normally "GEMINI.md".
+	File string
+	// Settings is the settings file relative to the base dir, normally ".gemini/settings.json".
+	Settings string
+	// Dir is the directory of the root context file relative to the base dir; empty for the base dir itself.
+	Dir string
+	// Flat puts every rule in the root context file.
+	Flat bool
+}
+
+func init() {
+	RegisterTarget(GeminiTarget{File: "GEMINI.md", Settings: filepath.Join(".gemini", "settings.json")})
+}
+
+// Name implements domain.Target.
+func (t GeminiTarget) Name() string { return "gemini" }
+
+// Layout implements domain.Target.
+func (t GeminiTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{SingleFile: true}
+}
+
+// Render implements domain.Target. With Consolidate every rule goes into the root context file.
+func (t GeminiTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	file, err := t.ContextFile(opts.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+	return nestedContextFiles(rules, t.Dir, file, t.Flat || opts.Consolidate), nil
+}
+
+// Global implements domain.GlobalTarget: Gemini CLI reads user context from ~/.gemini/GEMINI.md,
+// honoring contextFileName in ~/.gemini/settings.json.
+func (t GeminiTarget) Global() domain.Target {
+	return GeminiTarget{File: "GEMINI.md", Settings: filepath.Join(".gemini", "settings.json"), Dir: ".gemini", Flat: true}
+}
+
+// ContextFile returns the context file name Gemini CLI reads in baseDir: the contextFileName from
+// its settings, or File. When settings list several names, the first is used.
+func (t GeminiTarget) ContextFile(baseDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, t.Settings))
+	if os.IsNotExist(err) || baseDir == "" {
+		return t.File, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", t.Settings, err)
+	}
+	var settings struct {
+		ContextFileName json.RawMessage `json:"contextFileName"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "", fmt.Errorf("parse %s: %w", t.Settings, err)
+	}
+	if len(settings.ContextFileName) == 0 {
+		return t.File, nil
+	}
+	var name string
+	if err := json.Unmarshal(settings.ContextFileName, &name); err == nil && name != "" {
+		return name, nil
+	}
+	var names []string
+	if err := json.Unmarshal(settings.ContextFileName, &names); err == nil && len(names) > 0 && names[0] != "" {
+		return names[0], nil
+	}
+	return "", fmt.Errorf("%s: contextFileName must be a file name or a list of file names", t.Settings)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+	"ai-rules-link/internal/utils"
+)
+
+func TestGeminiTarget_HonorsContextFileName(t *testing.T) {
+	base := t.TempDir()
+	target := GeminiTarget{File: "GEMINI.md", Settings: filepath.Join(".gemini", "settings.json")}
+	rules := []domain.Rule{
+		{Name: "base", AlwaysApply: true, Body: "Base\n"},
+		{Name: "nextjs", Globs: []string{"web/**/*.tsx"}, Body: "Next\n"},
+	}
+	files, err := target.Render(rules, domain.RenderOptions{BaseDir: base})
+	if err != nil || len(files) != 2 || files[0].Path != "GEMINI.md" || files[1].Path != filepath.Join("web", "GEMINI.md") {
+		t.Fatalf("unexpected files: %+v, %v", files, err)
+	}
+
+	os.MkdirAll(filepath.Join(base, ".gemini"), 0755)
+	os.WriteFile(filepath.Join(base, ".gemini", "settings.json"), []byte(`{"contextFileName": ["AGENTS.md", "GEMINI.md"], "theme": "dark"}`), 0644)
+	files, err = target.Render(rules, domain.RenderOptions{BaseDir: base, Consolidate: true})
+	if err != nil || len(files) != 1 || files[0].Path != "AGENTS.md" {
+		t.Fatalf("unexpected files: %+v, %v", files, err)
+	}
+
+	os.WriteFile(filepath.Join(base, ".gemini", "settings.json"), []byte(`{"contextFileName": 3}`), 0644)
+	if _, err := target.Render(rules, domain.RenderOptions{BaseDir: base}); err == nil {
+		t.Error("expected an error for an invalid contextFileName")
+	}
+}
+
+func TestInstallRules_GeminiWritesContextFile(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc": "---\ndescription: Base\nglobs:\nalwaysApply: true\n---\nbase\n",
+		"gorules.mdc":   "---\ndescription: Go\nglobs:\nalwaysApply: true\n---\ngo\n",
+	})
+	dir := t.TempDir()
+	target, _ := LookupTarget("gemini")
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base", "go"}, Source: DirSource("test", canon), Target: target,
+		BaseDir: dir, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "GEMINI.md"))
+	if err != nil {
+		t.Fatalf("expected GEMINI.md: %v", err)
+	}
+	if block, _ := utils.ExtractManagedBlock(got); string(block) != "base\n\ngo\n" {
+		t.Errorf("unexpected block: %q", block)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, ".gemini", "context.mdc")); !os.IsNotExist(err) {
+		t.Errorf("context.mdc should not be created: %v", err)
+	}
+}
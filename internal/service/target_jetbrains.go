@@ -0,0 +1,50 @@
+package service
+
+import (
+	"path/filepath"
+
+	"ai-rules-link/internal/domain"
+)
+
+// JetBrainsTarget writes rules into JetBrains AI Assistant's .aiassistant/rules directory. The
+// rule type (always, by file pattern, by model decision or manual) is chosen in the IDE rather
+// than in the file, so each rule's scope is kept as a note above its body.
+type JetBrainsTarget struct {
+	// Dir is the rules directory relative to the base dir, normally ".aiassistant/rules".
+	Dir string
+}
+
+func init() {
+	RegisterTarget(JetBrainsTarget{Dir: ".aiassistant/rules"})
+}
+
+// Name implements domain.Target.
+func (t JetBrainsTarget) Name() string { return "jetbrains" }
+
+// Layout implements domain.Target.
+func (t JetBrainsTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: t.Dir}
+}
+
+// Render implements domain.Target. A rule without frontmatter is written unchanged, so it can be
+// linked to its source.
+func (t JetBrainsTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	if opts.Consolidate {
+		return []domain.TargetFile{{
+			Path:    filepath.Join(t.Dir, consolidatedMarkdown),
+			Content: joinMarkdown(rules),
+			Rules:   ruleNames(rules),
+		}}, nil
+	}
+	files := make([]domain.TargetFile, 0, len(rules))
+	for _, r := range rules {
+		content := []byte(renderMarkdown(r))
+		files = append(files, domain.TargetFile{
+			Path:       filepath.Join(t.Dir, r.Name+".md"),
+			Content:    content,
+			Rules:      []string{r.Name},
+			LinkSource: linkSource(r, content),
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestJetBrainsTarget_Render(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "base", Body: "Base\n", Raw: []byte("Base\n"), AlwaysApply: true, SourcePath: "/rules/baserules.mdc"},
+		{Name: "go", Globs: []string{"*.go"}, Body: "Go\n", SourcePath: "/rules/gorules.mdc"},
+	}
+	files, err := JetBrainsTarget{Dir: ".aiassistant/rules"}.Render(rules, domain.RenderOptions{})
+	if err != nil || len(files) != 2 {
+		t.Fatalf("unexpected result: %+v, %v", files, err)
+	}
+	if files[0].Path != ".aiassistant/rules/base.md" || files[0].LinkSource != "/rules/baserules.mdc" {
+		t.Errorf("plain rule should be linkable: %+v", files[0])
+	}
+	if want := "> Applies to files matching `*.go`.\n\nGo\n"; string(files[1].Content) != want || files[1].LinkSource != "" {
+		t.Errorf("unexpected go rule: %q, link %q", files[1].Content, files[1].LinkSource)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"ai-rules-link/internal/domain"
+)
+
+// WindsurfMaxChars is the most characters Windsurf reads from a single rule file.
+const WindsurfMaxChars = 12000
+
+// WindsurfGlobalMaxChars is the most characters Windsurf reads from its global rules file.
+const WindsurfGlobalMaxChars = 6000
+
+// WindsurfTarget writes rules into Windsurf's .windsurf/rules directory, translating the .mdc
+// frontmatter to Windsurf's trigger. Rules over WindsurfMaxChars are split into numbered parts.
+type WindsurfTarget struct {
+	// Dir is the rules directory relative to the base dir, normally ".windsurf/rules".
+	Dir string
+}
+
+func init() {
+	RegisterTarget(WindsurfTarget{Dir: ".windsurf/rules"})
+}
+
+// Name implements domain.Target.
+func (t WindsurfTarget) Name() string { return "windsurf" }
+
+// Layout implements domain.Target.
+func (t WindsurfTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: t.Dir, Frontmatter: []string{"trigger", "globs", "description"}}
+}
+
+// Render implements domain.Target. Consolidate writes one always-on file with each rule's scope
+// as a note.
+func (t WindsurfTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	if opts.Consolidate {
+		name := strings.TrimSuffix(consolidatedMarkdown, ".md")
+		return t.parts(name, "---\ntrigger: always_on\n---\n\n", string(joinMarkdown(rules)), ruleNames(rules), domain.Rule{}), nil
+	}
+	var files []domain.TargetFile
+	for _, r := range rules {
+		files = append(files, t.parts(r.Name, windsurfFrontmatter(r), r.Body, []string{r.Name}, r)...)
+	}
+	return files, nil
+}
+
+// Global implements domain.GlobalTarget: Windsurf reads user rules from a single global_rules.md.
+func (t WindsurfTarget) Global() domain.Target {
+	return windsurfGlobalTarget{File: filepath.Join(".codeium", "windsurf", "memories", "global_rules.md")}
+}
+
+// windsurfGlobalTarget writes every rule into a managed block of Windsurf's global rules file,
+// which has no frontmatter, so each rule's scope is kept as a note.
+type windsurfGlobalTarget struct {
+	File string
+}
+
+func (t windsurfGlobalTarget) Name() string { return "windsurf" }
+
+func (t windsurfGlobalTarget) Layout() domain.TargetLayout {
+	return domain.TargetLayout{Dir: filepath.Dir(t.File), SingleFile: true}
+}
+
+// Render warns rather than splits when the rules exceed WindsurfGlobalMaxChars, since Windsurf
+// reads only the one file.
+func (t windsurfGlobalTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	f := domain.TargetFile{Path: t.File, Content: joinMarkdown(rules), Rules: ruleNames(rules), Managed: true}
+	if n := utf8.RuneCount(f.Content); n > WindsurfGlobalMaxChars {
+		f.Warning = fmt.Sprintf("%s has %d characters of rules but Windsurf reads only the first %d; install fewer rules globally.", t.File, n, WindsurfGlobalMaxChars)
+	}
+	return []domain.TargetFile{f}, nil
+}
+
+// parts renders header and body as name.md, splitting the body into name-2.md, name-3.md and so
+// on when the file would exceed WindsurfMaxChars.
+func (t WindsurfTarget) parts(name, header, body string, ruleNames []string, r domain.Rule) []domain.TargetFile {
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	chunks := splitMarkdown(body, WindsurfMaxChars-utf8.RuneCountInString(header))
+	files := make([]domain.TargetFile, len(chunks))
+	for i, chunk := range chunks {
+		file := name + ".md"
+		if i > 0 {
+			file = fmt.Sprintf("%s-%d.md", name, i+1)
+		}
+		content := []byte(header + chunk)
+		files[i] = domain.TargetFile{
+			Path:       filepath.Join(t.Dir, file),
+			Content:    content,
+			Rules:      ruleNames,
+			LinkSource: linkSource(r, content),
+		}
+	}
+	return files
+}
+
+// windsurfFrontmatter translates a rule's activation to Windsurf's trigger frontmatter.
+func windsurfFrontmatter(r domain.Rule) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	switch r.Activation() {
+	case domain.ActivationAlways:
+		sb.WriteString("trigger: always_on\n")
+	case domain.ActivationGlob:
+		sb.WriteString("trigger: glob\n")
+		// Windsurf writes globs unquoted itself, so its parser accepts a leading "*".
+		fmt.Fprintf(&sb, "globs: %s\n", domain.JoinGlobs(r.Globs))
+	case domain.ActivationModelDecision:
+		sb.WriteString("trigger: model_decision\n")
+	case domain.ActivationManual:
+		sb.WriteString("trigger: manual\n")
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&sb, "description: %q\n", r.Description)
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"ai-rules-link/internal/domain"
+)
+
+func TestWindsurfTarget_TranslatesTriggers(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "base", AlwaysApply: true, Body: "Base\n"},
+		{Name: "go", Globs: []string{"*.go", "cmd/**"}, Body: "Go\n"},
+		{Name: "docs", Description: "Writing docs", Body: "Docs\n"},
+		{Name: "release", Body: "Release\n"},
+	}
+	files, err := WindsurfTarget{Dir: ".windsurf/rules"}.Render(rules, domain.RenderOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		".windsurf/rules/base.md":    "---\ntrigger: always_on\n---\n\nBase\n",
+		".windsurf/rules/go.md":      "---\ntrigger: glob\nglobs: *.go,cmd/**\n---\n\nGo\n",
+		".windsurf/rules/docs.md":    "---\ntrigger: model_decision\ndescription: \"Writing docs\"\n---\n\nDocs\n",
+		".windsurf/rules/release.md": "---\ntrigger: manual\n---\n\nRelease\n",
+	}
+	if len(files) != len(want) {
+		t.Fatalf("expected %d files, got %+v", len(want), files)
+	}
+	for _, f := range files {
+		if string(f.Content) != want[f.Path] {
+			t.Errorf("%s:\n%q\nwant:\n%q", f.Path, f.Content, want[f.Path])
+		}
+	}
+}
+
+func TestWindsurfTarget_SplitsLargeRules(t *testing.T) {
+	para := strings.Repeat("x", 5000) + "\n\n"
+	rules := []domain.Rule{{Name: "big", AlwaysApply: true, Body: strings.Repeat(para, 4)}}
+	files, err := WindsurfTarget{Dir: ".windsurf/rules"}.Render(rules, domain.RenderOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 || files[0].Path != ".windsurf/rules/big.md" || files[1].Path != ".windsurf/rules/big-2.md" {
+		t.Fatalf("unexpected parts: %d", len(files))
+	}
+	for _, f := range files {
+		if n := utf8.RuneCount(f.Content); n > WindsurfMaxChars {
+			t.Errorf("%s has %d characters", f.Path, n)
+		}
+		if !strings.HasPrefix(string(f.Content), "---\ntrigger: always_on\n---\n\n") {
+			t.Errorf("%s is missing its frontmatter", f.Path)
+		}
+	}
+}
+
+func TestWindsurfTarget_RemovesPartsNoLongerNeeded(t *testing.T) {
+	para := strings.Repeat("x", 5000) + "\n\n"
+	big := "---\ndescription: Big\nglobs:\nalwaysApply: true\n---\n" + strings.Repeat(para, 4)
+	canon := newCanonicalDir(t, map[string]string{"bigrules.mdc": big})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	target, _ := LookupTarget("windsurf")
+	opts := InstallOptions{
+		Rules: []string{"big"}, Mode: domain.ModeCopy, Source: DirSource("test", canon), Target: target,
+		BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	}
+	if err := InstallRules(context.Background(), opts); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	part := filepath.Join(project, ".windsurf", "rules", "big-2.md")
+	if _, err := os.Stat(part); err != nil {
+		t.Fatalf("expected a second part: %v", err)
+	}
+
+	// The rule shrinks: reinstalling removes the second part.
+	os.WriteFile(filepath.Join(canon, "bigrules.mdc"), []byte(strings.Replace(big, para, "", 2)), 0644)
+	if err := InstallRules(context.Background(), opts); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	if _, err := os.Stat(part); !os.IsNotExist(err) {
+		t.Errorf("stale part left behind: %v", err)
+	}
+	if _, ok := manifest.Lookup(part); ok {
+		t.Error("stale part still in the manifest")
+	}
+
+	// Sync removes it too.
+	os.WriteFile(filepath.Join(canon, "bigrules.mdc"), []byte(big), 0644)
+	InstallRules(context.Background(), opts)
+	os.WriteFile(filepath.Join(canon, "bigrules.mdc"), []byte(strings.Replace(big, para, "", 2)), 0644)
+	if _, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if _, err := os.Stat(part); !os.IsNotExist(err) {
+		t.Errorf("sync left the stale part behind: %v", err)
+	}
+}
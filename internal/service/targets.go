@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"ai-rules-link/internal/domain"
+)
+
+// targets holds every registered target by name.
+var targets = map[string]domain.Target{}
+
+// RegisterTarget makes a target available to --target. Registering a name twice replaces the target.
+func RegisterTarget(t domain.Target) {
+	targets[t.Name()] = t
+}
+
+// LookupTarget returns the registered target with the given name.
+func LookupTarget(name string) (domain.Target, error) {
+	t, ok := targets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q (available: %s)", name, strings.Join(TargetNames(), ", "))
+	}
+	return t, nil
+}
+
+// TargetNames returns the names of all registered targets, sorted.
+func TargetNames() []string {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultTarget is used when no --target is given.
+const DefaultTarget = "cursor"
+
+// mergeRaw joins rules in order the way the consolidate mode always has: each file followed by a newline.
+func mergeRaw(rules []domain.Rule) []byte {
+	var merged []byte
+	for _, r := range rules {
+		merged = append(merged, r.Raw...)
+		merged = append(merged, '\n')
+	}
+	return merged
+}
+
+func ruleNames(rules []domain.Rule) []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// ScopeNote describes, in prose, when a rule applies. Tools without glob or description frontmatter
+// get this note instead, so the scoping guidance is not lost. Always-applied rules need no note.
+func ScopeNote(r domain.Rule) string {
+	switch r.Activation() {
+	case domain.ActivationGlob:
+		quoted := make([]string, len(r.Globs))
+		for i, g := range r.Globs {
+			quoted[i] = "`" + g + "`"
+		}
+		return "Applies to files matching " + strings.Join(quoted, ", ") + "."
+	case domain.ActivationModelDecision:
+		return "Apply when relevant: " + strings.TrimSuffix(r.Description, ".") + "."
+	case domain.ActivationManual:
+		return "Apply only when this rule is mentioned explicitly."
+	default:
+		return ""
+	}
+}
+
+// renderMarkdown returns a rule as plain markdown without frontmatter, with its scope as a leading note.
+func renderMarkdown(r domain.Rule) string {
+	body := r.Body
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	if note := ScopeNote(r); note != "" {
+		return "> " + note + "\n\n" + body
+	}
+	return body
+}
+
+// joinMarkdown renders rules one after another, separated by blank lines.
+func joinMarkdown(rules []domain.Rule) []byte {
+	parts := make([]string, len(rules))
+	for i, r := range rules {
+		parts[i] = renderMarkdown(r)
+	}
+	return []byte(strings.Join(parts, "\n"))
+}
+
+// linkSource returns the rule's source path when content is the source file byte for byte, so link
+// modes can point at it, and "" when the target changed anything.
+func linkSource(r domain.Rule, content []byte) string {
+	if r.SourcePath == "" || string(content) != string(r.Raw) {
+		return ""
+	}
+	return r.SourcePath
+}
+
+// splitMarkdown cuts body into parts of at most max characters for tools that limit rule size.
+// Parts end at blank lines where possible and at line ends otherwise; a single line longer than
+// max is kept whole.
+func splitMarkdown(body string, max int) []string {
+	if utf8.RuneCountInString(body) <= max {
+		return []string{body}
+	}
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+	add := func(piece string) {
+		if utf8.RuneCountInString(current.String())+utf8.RuneCountInString(piece) > max {
+			flush()
+		}
+		current.WriteString(piece)
+	}
+	for _, para := range strings.SplitAfter(body, "\n\n") {
+		if utf8.RuneCountInString(para) <= max {
+			add(para)
+			continue
+		}
+		for _, line := range strings.SplitAfter(para, "\n") {
+			add(line)
+		}
+	}
+	flush()
+	return parts
+}
+
+// nestedContextFiles renders rules into managed blocks of file, for tools that read the nearest
+// context file in a directory hierarchy. Glob rules whose globs share a directory go into that
+// directory's file; everything else, or every rule when flat is set, goes into the root file in
+// rootDir. The root file comes first, then nested files by directory.
+func nestedContextFiles(rules []domain.Rule, rootDir, file string, flat bool) []domain.TargetFile {
+	byDir := map[string][]domain.Rule{}
+	for _, r := range rules {
+		dir := ""
+		if !flat && r.Activation() == domain.ActivationGlob {
+			dir = domain.CommonGlobDir(r.Globs)
+		}
+		byDir[dir] = append(byDir[dir], r)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	files := make([]domain.TargetFile, 0, len(dirs))
+	for _, dir := range dirs {
+		path := filepath.Join(filepath.FromSlash(dir), file)
+		if dir == "" {
+			path = filepath.Join(rootDir, file)
+		}
+		files = append(files, domain.TargetFile{
+			Path:    path,
+			Content: joinMarkdown(byDir[dir]),
+			Rules:   ruleNames(byDir[dir]),
+			Managed: true,
+		})
+	}
+	return files
+}
+
+// UserTarget returns the variant of t that installs where its tool reads user-level rules. It
+// returns t and false when the tool has no such location, so the project layout is used under the
+// home directory instead.
+func UserTarget(t domain.Target) (domain.Target, bool) {
+	if g, ok := t.(domain.GlobalTarget); ok {
+		return g.Global(), true
+	}
+	return t, false
+}
+
+// entryTarget returns the name of the target a manifest entry was installed for.
+func entryTarget(e ManifestEntry) string {
+	if e.Target == "" {
+		return DefaultTarget
+	}
+	return e.Target
+}
+
+// lookupEntryTarget returns the target a manifest entry was installed with, as it renders the entry.
+func lookupEntryTarget(e ManifestEntry) (domain.Target, error) {
+	t, err := lookupEntryTool(e)
+	if err != nil {
+		return nil, err
+	}
+	if e.Scoped {
+		t = ScopedTarget{Target: t, Dir: e.Dir}
+	}
+	if e.AutoGlobs {
+		t = AutoGlobTarget{Target: t}
+	}
+	return t, nil
+}
+
+// lookupEntryTool returns the target a manifest entry was installed for, without the wrappers that
+// change how rules are rendered, so optional interfaces such as domain.ConfiguredTarget are visible.
+func lookupEntryTool(e ManifestEntry) (domain.Target, error) {
+	t, err := LookupTarget(entryTarget(e))
+	if err != nil || !e.Global {
+		return t, err
+	}
+	t, _ = UserTarget(t)
+	return t, nil
+}
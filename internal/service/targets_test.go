@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-rules-link/internal/domain"
+)
+
+// upperTarget is a test target that transforms rule content, so it can never be linked.
+type upperTarget struct{}
+
+func (upperTarget) Name() string                { return "upper" }
+func (upperTarget) Layout() domain.TargetLayout { return domain.TargetLayout{Dir: "upper"} }
+func (upperTarget) Render(rules []domain.Rule, opts domain.RenderOptions) ([]domain.TargetFile, error) {
+	var files []domain.TargetFile
+	for _, r := range rules {
+		files = append(files, domain.TargetFile{
+			Path:    filepath.Join("upper", r.Name+".md"),
+			Content: []byte(strings.ToUpper(r.Body)),
+			Rules:   []string{r.Name},
+		})
+	}
+	return files, nil
+}
+
+func TestLookupTarget(t *testing.T) {
+	if target, err := LookupTarget("Cursor"); err != nil || target.Name() != "cursor" {
+		t.Errorf("unexpected result: %v, %v", target, err)
+	}
+	if _, err := LookupTarget("nope"); err == nil || !strings.Contains(err.Error(), "cursor") {
+		t.Errorf("expected error listing available targets, got: %v", err)
+	}
+}
+
+func TestInstallRules_GeneratedFilesAreCopiedInLinkMode(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "---\nalwaysApply: true\n---\ngo rules\n"})
+	base := t.TempDir()
+	manifest, _ := LoadManifest(base)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:    []string{"go"},
+		Mode:     domain.ModeSymlink,
+		Source:   DirSource("test", canon),
+		Target:   upperTarget{},
+		BaseDir:  base,
+		Manifest: manifest,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dst := filepath.Join(base, "upper", "go.md")
+	info, err := os.Lstat(dst)
+	if err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("expected a regular file, got %v, %v", info, err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "GO RULES\n" {
+		t.Errorf("unexpected content: %q", got)
+	}
+	if e, ok := manifest.Lookup(dst); !ok || e.Target != "upper" || e.Mode != domain.ModeCopy {
+		t.Errorf("unexpected manifest entry: %+v, %v", e, ok)
+	}
+}
+
+func TestUserTarget_Locations(t *testing.T) {
+	rules := []domain.Rule{{Name: "base", AlwaysApply: true, Body: "Base\n"}, {Name: "web", Globs: []string{"web/**"}, Body: "Web\n"}}
+	want := map[string][]string{
+		"claude":   {filepath.Join(".claude", "rules", "base.md"), filepath.Join(".claude", "rules", "web.md"), filepath.Join(".claude", "CLAUDE.md")},
+		"gemini":   {filepath.Join(".gemini", "GEMINI.md")},
+		"agents":   {filepath.Join(".codex", "AGENTS.md")},
+		"windsurf": {filepath.Join(".codeium", "windsurf", "memories", "global_rules.md")},
+		"continue": {filepath.Join(".continue", "rules", "base.md"), filepath.Join(".continue", "rules", "web.md")},
+	}
+	for name, paths := range want {
+		project, _ := LookupTarget(name)
+		target, ok := UserTarget(project)
+		if !ok {
+			t.Errorf("%s: expected a user-level location", name)
+			continue
+		}
+		files, err := target.Render(rules, domain.RenderOptions{BaseDir: t.TempDir()})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, f.Path)
+		}
+		if strings.Join(got, " ") != strings.Join(paths, " ") {
+			t.Errorf("%s: got %v, want %v", name, got, paths)
+		}
+	}
+	if _, ok := UserTarget(CursorTarget{Dir: ".cursor/rules"}); ok {
+		t.Error("cursor keeps user rules in its settings and should have no user-level location")
+	}
+}
+
+func TestInstallRules_GlobalRecordsUserLocation(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"baserules.mdc": "Base\n"})
+	home := t.TempDir()
+	manifest, _ := LoadManifest(home)
+	claude, _ := LookupTarget("claude")
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base"}, Mode: domain.ModeCopy, Source: DirSource("test", canon), Target: claude,
+		BaseDir: home, Global: true, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(home, ".claude", "CLAUDE.md"))
+	if err != nil || !strings.Contains(string(got), "@rules/base.md\n") {
+		t.Errorf("unexpected ~/.claude/CLAUDE.md: %q, %v", got, err)
+	}
+	e, ok := manifest.Lookup(filepath.Join(home, ".claude", "CLAUDE.md"))
+	if !ok || !e.Global {
+		t.Fatalf("expected a global manifest entry, got %+v", e)
+	}
+	if _, err := renderEntry(manifest, e, DirSource("test", canon)); err != nil {
+		t.Errorf("global entry should re-render: %v", err)
+	}
+}
+
+func TestWindsurfGlobal_WarnsOverLimit(t *testing.T) {
+	target, _ := UserTarget(WindsurfTarget{Dir: ".windsurf/rules"})
+	rules := []domain.Rule{{Name: "big", AlwaysApply: true, Body: strings.Repeat("x", WindsurfGlobalMaxChars+1)}}
+	files, err := target.Render(rules, domain.RenderOptions{})
+	if err != nil || len(files) != 1 || files[0].Warning == "" {
+		t.Errorf("expected one file with a size warning, got %+v, %v", files, err)
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-rules-link/internal/domain"
+)
+
+// SyncOptions configures SyncInstalls.
+type SyncOptions struct {
+	Manifest *Manifest
+	Embedded fs.FS // used for entries installed from the embedded rules
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// SyncInstalls brings every generated file in the manifest up to date with its rule source.
+// Copies and consolidated files are rewritten unless they were edited since they were installed;
+// hard links are recreated when an editor replaced the canonical file. Symlinks need no work.
+// It returns the number of files updated.
+func SyncInstalls(ctx context.Context, opts SyncOptions) (int, error) {
+	updated := 0
+	for _, e := range append([]ManifestEntry{}, opts.Manifest.Entries...) {
+		if err := ctx.Err(); err != nil {
+			return updated, err
+		}
+		src := opts.Manifest.entrySource(e, opts.Embedded)
+		dst := opts.Manifest.Abs(e)
+		switch e.Mode {
+		case domain.ModeCopy, domain.ModeConsolidate:
+			f, err := renderEntry(opts.Manifest, e, src)
+			if errors.Is(err, errNotRendered) {
+				if target, lerr := lookupEntryTarget(e); lerr == nil {
+					if err := removeStaleEntry(opts.Manifest, e, target, opts.Stdout); err != nil {
+						return updated, err
+					}
+					continue
+				}
+			}
+			if err != nil {
+				fmt.Fprintf(opts.Stderr, "Could not sync %s: %v\n", e.Path, err)
+				continue
+			}
+			current, err := installedContent(dst, e.Managed)
+			if err == nil && bytes.Equal(current, f.Content) {
+				continue
+			}
+			if err == nil && e.SHA256 != "" && ContentHash(current) != e.SHA256 {
+				fmt.Fprintf(opts.Stdout, "[ai-rules-link] Skipping %s: modified since it was installed.\n", e.Path)
+				continue
+			}
+			if err := writeTargetFile(dst, f.Content, e.Managed); err != nil {
+				return updated, err
+			}
+			e.SHA256 = ContentHash(f.Content)
+			opts.Manifest.Record(e)
+		case domain.ModeHardlink:
+			srcPath := src.Path(e.Rules[0])
+			srcInfo, err := os.Stat(srcPath)
+			if err != nil {
+				fmt.Fprintf(opts.Stderr, "Could not sync %s: %v\n", e.Path, err)
+				continue
+			}
+			if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+				continue
+			}
+			os.Remove(dst)
+			if err := os.Link(srcPath, dst); err != nil {
+				return updated, fmt.Errorf("relink %s: %w", dst, err)
+			}
+		default:
+			continue
+		}
+		fmt.Fprintf(opts.Stdout, "Updated %s\n", e.Path)
+		updated++
+	}
+	return updated, nil
+}
+
+// WatchPaths returns the files and directories whose changes should trigger a sync: the manifest
+// itself, the project's overrides and packages files and every rule directory it installed from.
+func WatchPaths(m *Manifest) []string {
+	paths := []string{filepath.Join(m.Dir(), ManifestFilename), filepath.Join(m.Dir(), OverridesFilename), filepath.Join(m.Dir(), PackagesFilename)}
+	seen := map[string]bool{}
+	for _, e := range m.Entries {
+		src := m.entrySource(e, nil)
+		if src.Embedded() || seen[src.Dir] {
+			continue
+		}
+		seen[src.Dir] = true
+		paths = append(paths, src.Dir)
+	}
+	return paths
+}
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// Paths returns the paths to poll; it is called again after every change so new sources are picked up.
+	Paths    func() []string
+	Interval time.Duration // how often to poll
+	Debounce time.Duration // how long changes must settle before OnChange runs
+	OnChange func(ctx context.Context) error
+	Stderr   io.Writer
+}
+
+// Watch polls Paths until ctx is cancelled and calls OnChange once changes have settled for Debounce.
+// Polling keeps it dependency free and working on any filesystem without extra services.
+func Watch(ctx context.Context, opts WatchOptions) error {
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	last := snapshot(opts.Paths())
+	var pendingSince time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			current := snapshot(opts.Paths())
+			if current != last {
+				last = current
+				pendingSince = now
+				continue
+			}
+			if pendingSince.IsZero() || now.Sub(pendingSince) < opts.Debounce {
+				continue
+			}
+			pendingSince = time.Time{}
+			if err := opts.OnChange(ctx); err != nil {
+				fmt.Fprintf(opts.Stderr, "Sync error: %v\n", err)
+			}
+			// Pick up our own writes (e.g. the manifest) so they do not trigger another run.
+			last = snapshot(opts.Paths())
+		}
+	}
+}
+
+// snapshot fingerprints the given paths by name, size and modification time.
+// Directories are listed one level deep, which is how rule sources are laid out.
+func snapshot(paths []string) string {
+	var lines []string
+	add := func(path string, info os.FileInfo) {
+		lines = append(lines, fmt.Sprintf("%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano()))
+	}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			lines = append(lines, p+"\x00missing")
+			continue
+		}
+		add(p, info)
+		if !info.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil {
+				add(filepath.Join(p, entry.Name()), info)
+			}
+		}
+	}
+	sort.Strings(lines)
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// errNotRendered means a manifest entry's target no longer generates its file from its rules.
+var errNotRendered = errors.New("no longer renders")
+
+// renderEntry re-renders the file a manifest entry describes from the current rule source.
+func renderEntry(m *Manifest, e ManifestEntry, src RuleSource) (domain.TargetFile, error) {
+	target, err := lookupEntryTarget(e)
+	if err != nil {
+		return domain.TargetFile{}, err
+	}
+	patches, err := entryPatches(m, e)
+	if err != nil {
+		return domain.TargetFile{}, err
+	}
+	rules := make([]domain.Rule, 0, len(e.Rules))
+	for _, rule := range e.Rules {
+		r, err := src.LoadRuleSpec(rule)
+		if err != nil {
+			return domain.TargetFile{}, fmt.Errorf("could not read %s: %w", RuleFilename(rule), err)
+		}
+		if r, err = patchRule(r, patches); err != nil {
+			return domain.TargetFile{}, err
+		}
+		rules = append(rules, r)
+	}
+	files, err := target.Render(rules, domain.RenderOptions{Consolidate: e.Mode == domain.ModeConsolidate, BaseDir: m.Base(e)})
+	if err != nil {
+		return domain.TargetFile{}, err
+	}
+	want := m.Abs(e)
+	for _, f := range files {
+		if filepath.Join(m.Base(e), f.Path) == want {
+			return f, nil
+		}
+	}
+	// The rules directory may have been moved with DEST_RULES_PATH: match the file's path inside
+	// it. Files outside a rules directory, such as nested AGENTS.md files, must match exactly.
+	if dir := target.Layout().Dir; dir != "" {
+		for _, f := range files {
+			rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(f.Path))
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if strings.HasSuffix(want, string(filepath.Separator)+rel) {
+				return f, nil
+			}
+		}
+	}
+	return domain.TargetFile{}, fmt.Errorf("%s target %w %s", target.Name(), errNotRendered, e.Path)
+}
+
+// writeInstalled replaces dst with content, removing any link first so it is not written through.
+func writeInstalled(dst string, content []byte) error {
+	os.Remove(dst)
+	if err := os.WriteFile(dst, content, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", dst, err)
+	}
+	return nil
+}
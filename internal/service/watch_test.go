@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ai-rules-link/internal/domain"
+)
+
+func installForSync(t *testing.T, canon, project string, mode domain.InstallMode, rules ...string) *Manifest {
+	t.Helper()
+	manifest, _ := LoadManifest(project)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules:    rules,
+		Mode:     mode,
+		Source:   DirSource("test", canon),
+		BaseDir:  project,
+		Manifest: manifest,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	return manifest
+}
+
+func TestSyncInstalls_RefreshesCopiesAndConsolidated(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go v1", "baserules.mdc": "base v1"})
+	project := t.TempDir()
+	manifest := installForSync(t, canon, project, domain.ModeCopy, "go")
+	consolidated := installForSync(t, canon, project, domain.ModeConsolidate, "base", "go")
+	manifest.Entries = append(manifest.Entries, consolidated.Entries...)
+
+	os.WriteFile(filepath.Join(canon, "gorules.mdc"), []byte("go v2"), 0644)
+	n, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 updated files, got %d", n)
+	}
+	if got, _ := os.ReadFile(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); string(got) != "go v2" {
+		t.Errorf("copy not refreshed: %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(project, ".cursor", "rules", ConsolidatedFilename)); string(got) != "base v1\ngo v2\n" {
+		t.Errorf("consolidated file not refreshed: %q", got)
+	}
+}
+
+func TestSyncInstalls_KeepsUserEdits(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go v1"})
+	project := t.TempDir()
+	manifest := installForSync(t, canon, project, domain.ModeCopy, "go")
+	dst := filepath.Join(project, ".cursor", "rules", "gorules.mdc")
+	os.WriteFile(dst, []byte("edited"), 0644)
+	os.WriteFile(filepath.Join(canon, "gorules.mdc"), []byte("go v2"), 0644)
+
+	n, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil || n != 0 {
+		t.Fatalf("expected no updates, got %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "edited" {
+		t.Errorf("user edit was overwritten: %q", got)
+	}
+}
+
+func TestSyncInstalls_RelinksReplacedHardlink(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go v1"})
+	project := t.TempDir()
+	manifest := installForSync(t, canon, project, domain.ModeHardlink, "go")
+	// Editors often save by writing a new file and renaming it over the old one.
+	src := filepath.Join(canon, "gorules.mdc")
+	os.Remove(src)
+	os.WriteFile(src, []byte("go v2"), 0644)
+
+	if _, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(project, ".cursor", "rules", "gorules.mdc")); string(got) != "go v2" {
+		t.Errorf("hard link not refreshed: %q", got)
+	}
+}
+
+func TestWatch_DebouncesChanges(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, WatchOptions{
+			Paths:    func() []string { return []string{dir} },
+			Interval: 5 * time.Millisecond,
+			Debounce: 30 * time.Millisecond,
+			OnChange: func(context.Context) error {
+				calls.Add(1)
+				return nil
+			},
+			Stderr: io.Discard,
+		})
+	}()
+
+	for i := 0; i < 3; i++ {
+		os.WriteFile(filepath.Join(dir, "gorules.mdc"), []byte{byte('a' + i)}, 0644)
+		time.Sleep(10 * time.Millisecond)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(60 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected one debounced sync, got %d", got)
+	}
+}
+
+func TestSyncInstalls_MovedRulesDir(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{"gorules.mdc": "go v1"})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"go"}, Mode: domain.ModeCopy, Source: DirSource("test", canon), Target: CursorTarget{Dir: "ai/rules"},
+		BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	os.WriteFile(filepath.Join(canon, "gorules.mdc"), []byte("go v2"), 0644)
+	if n, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard}); err != nil || n != 1 {
+		t.Fatalf("expected 1 update, got %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(project, "ai", "rules", "gorules.mdc")); string(got) != "go v2" {
+		t.Errorf("copy in the moved rules directory not refreshed: %q", got)
+	}
+}
+
+func TestSyncInstalls_NestedFileNotRenderedAnyMore(t *testing.T) {
+	canon := newCanonicalDir(t, map[string]string{
+		"baserules.mdc": "---\nalwaysApply: true\n---\nBase\n",
+		"gorules.mdc":   "---\nglobs: web/**/*.go\nalwaysApply: false\n---\nGo\n",
+	})
+	project := t.TempDir()
+	manifest, _ := LoadManifest(project)
+	agents, _ := LookupTarget("agents")
+	err := InstallRules(context.Background(), InstallOptions{
+		Rules: []string{"base", "go"}, Mode: domain.ModeCopy, Source: DirSource("test", canon), Target: agents,
+		BaseDir: project, Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	nested := filepath.Join(project, "web", "AGENTS.md")
+	if _, ok := manifest.Lookup(nested); !ok {
+		t.Fatalf("expected web/AGENTS.md to be installed, got %+v", manifest.Entries)
+	}
+
+	// The go rule now covers the whole project, so it moves to the root AGENTS.md.
+	os.WriteFile(filepath.Join(canon, "gorules.mdc"), []byte("---\nglobs: **/*.go\nalwaysApply: false\n---\nGo\n"), 0644)
+	if _, err := SyncInstalls(context.Background(), SyncOptions{Manifest: manifest, Stdout: io.Discard, Stderr: io.Discard}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if got, _ := os.ReadFile(nested); strings.Contains(string(got), "Go") {
+		t.Errorf("web/AGENTS.md was given the root file's content: %q", got)
+	}
+	if _, ok := manifest.Lookup(nested); ok {
+		t.Error("web/AGENTS.md is still recorded")
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"io/fs"
+	"path"
+
+	"ai-rules-link/internal/domain"
+)
+
+// RuleCandidate is one location checked when looking up a rule.
+type RuleCandidate struct {
+	Source RuleSource
+	// Path is the rule file checked: on disk, or inside the embedded rules.
+	Path string
+	// Available means the location exists, so the lookup can stop there.
+	Available bool
+	// Exists means the rule file is there; SHA256 is then the hash of its content.
+	Exists bool
+	SHA256 string
+	// Selected marks the location rules are installed from. Only the first available location is
+	// used, so a rule missing there is not installed even if a later location has it.
+	Selected bool
+}
+
+// WhichRule returns every location checked for rule, in lookup order, with what each holds. rule
+// may select sections, as in "go#testing"; the rule file is looked up all the same.
+func WhichRule(projectDir string, embedded fs.FS, rule string) []RuleCandidate {
+	rule, _ = domain.ParseRuleSpec(rule)
+	sources := CandidateSources(projectDir, embedded)
+	selected := resolvedIndex(sources)
+	candidates := make([]RuleCandidate, len(sources))
+	for i, src := range sources {
+		c := RuleCandidate{Source: src, Path: src.Path(rule), Available: src.Embedded() || isDir(src.Dir), Selected: i == selected}
+		if src.Embedded() {
+			c.Path = path.Join("rules", RuleFilename(rule))
+		}
+		if raw, err := src.ReadRule(rule); err == nil {
+			c.Exists = true
+			c.SHA256 = ContentHash(raw)
+		}
+		candidates[i] = c
+	}
+	return candidates
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWhichRule(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	project := t.TempDir()
+	os.MkdirAll(filepath.Join(home, "ai-rules"), 0755)
+	os.WriteFile(filepath.Join(home, "ai-rules", "pythonrules.mdc"), []byte("python"), 0644)
+	embedded := fstest.MapFS{"rules/gorules.mdc": {Data: []byte("go")}}
+
+	candidates := WhichRule(project, embedded, "go")
+	if len(candidates) != 3 {
+		t.Fatalf("expected project, home and embedded candidates, got %+v", candidates)
+	}
+	project0, home1, embedded2 := candidates[0], candidates[1], candidates[2]
+	if project0.Available || project0.Exists || project0.Selected {
+		t.Errorf("missing .ai-rules reported as usable: %+v", project0)
+	}
+	if !home1.Available || home1.Exists || !home1.Selected {
+		t.Errorf("~/ai-rules should be selected without the rule: %+v", home1)
+	}
+	if !embedded2.Exists || embedded2.Selected || embedded2.SHA256 != ContentHash([]byte("go")) || embedded2.Path != "rules/gorules.mdc" {
+		t.Errorf("unexpected embedded candidate: %+v", embedded2)
+	}
+}
+
+func TestWhichRule_IgnoresSections(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	embedded := fstest.MapFS{"rules/gorules.mdc": {Data: []byte("go")}}
+
+	candidates := WhichRule("", embedded, "go#testing#concurrency")
+	last := candidates[len(candidates)-1]
+	if !last.Exists || last.Path != "rules/gorules.mdc" {
+		t.Errorf("expected go#testing to look up gorules.mdc, got %+v", last)
+	}
+}
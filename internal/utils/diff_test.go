@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff_Equal(t *testing.T) {
+	if d := UnifiedDiff("a", "b", []byte("same\n"), []byte("same\n")); d != "" {
+		t.Errorf("expected empty diff, got %q", d)
+	}
+}
+
+func TestUnifiedDiff_ChangedLine(t *testing.T) {
+	a := []byte("one\ntwo\nthree\n")
+	b := []byte("one\n2\nthree\n")
+	want := "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+	if d := UnifiedDiff("a", "b", a, b); d != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", d, want)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", i+1)
+		a = append(a, line)
+		b = append(b, line)
+	}
+	b[1] = "changed early"
+	b[18] = "changed late"
+	d := UnifiedDiff("a", "b", []byte(strings.Join(a, "\n")), []byte(strings.Join(b, "\n")))
+	if n := strings.Count(d, "@@ -"); n != 2 {
+		t.Errorf("expected 2 hunks, got %d:\n%s", n, d)
+	}
+	if !strings.Contains(d, "+changed early") || !strings.Contains(d, "+changed late") {
+		t.Errorf("diff missing changes:\n%s", d)
+	}
+}
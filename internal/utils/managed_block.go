@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"bytes"
+)
+
+// Markers delimiting the part of a hand-written file that ai-rules-link owns.
+const (
+	ManagedBegin = "<!-- BEGIN ai-rules-link -->"
+	ManagedEnd   = "<!-- END ai-rules-link -->"
+)
+
+// ExtractManagedBlock returns the content between the managed block markers, excluding the marker lines.
+func ExtractManagedBlock(content []byte) ([]byte, bool) {
+	start, end, ok := managedBounds(content)
+	if !ok {
+		return nil, false
+	}
+	inner := content[start:end]
+	inner = inner[bytes.IndexByte(inner, '\n')+1:]
+	return inner[:bytes.LastIndex(inner, []byte(ManagedEnd))], true
+}
+
+// ReplaceManagedBlock returns content with its managed block set to block. Content outside the
+// markers is preserved; if there is no block yet, one is appended after a blank line.
+func ReplaceManagedBlock(content, block []byte) []byte {
+	var wrapped bytes.Buffer
+	wrapped.WriteString(ManagedBegin + "\n")
+	wrapped.Write(block)
+	if len(block) > 0 && block[len(block)-1] != '\n' {
+		wrapped.WriteByte('\n')
+	}
+	wrapped.WriteString(ManagedEnd + "\n")
+
+	start, end, ok := managedBounds(content)
+	if !ok {
+		out := bytes.TrimRight(content, "\n")
+		if len(out) > 0 {
+			out = append(out, '\n', '\n')
+		}
+		return append(out, wrapped.Bytes()...)
+	}
+	out := append([]byte{}, content[:start]...)
+	out = append(out, wrapped.Bytes()...)
+	return append(out, content[end:]...)
+}
+
+// RemoveManagedBlock returns content without its managed block and the blank line before it.
+func RemoveManagedBlock(content []byte) []byte {
+	start, end, ok := managedBounds(content)
+	if !ok {
+		return content
+	}
+	before := bytes.TrimRight(content[:start], "\n")
+	after := content[end:]
+	if len(before) > 0 {
+		before = append(before, '\n')
+		if len(bytes.TrimLeft(after, "\n")) > 0 {
+			before = append(before, '\n')
+		}
+	}
+	return append(before, bytes.TrimLeft(after, "\n")...)
+}
+
+// managedBounds returns the byte range of the managed block including both marker lines.
+func managedBounds(content []byte) (start, end int, ok bool) {
+	start = bytes.Index(content, []byte(ManagedBegin))
+	if start < 0 {
+		return 0, 0, false
+	}
+	rel := bytes.Index(content[start:], []byte(ManagedEnd))
+	if rel < 0 {
+		return 0, 0, false
+	}
+	end = start + rel + len(ManagedEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
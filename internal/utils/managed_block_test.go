@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestReplaceManagedBlock_AppendsAndReplaces(t *testing.T) {
+	original := []byte("# Project\n\nHand-written notes.\n")
+	once := ReplaceManagedBlock(original, []byte("@rules/go.md\n"))
+	want := "# Project\n\nHand-written notes.\n\n" + ManagedBegin + "\n@rules/go.md\n" + ManagedEnd + "\n"
+	if string(once) != want {
+		t.Fatalf("unexpected content:\n%s", once)
+	}
+	withTail := append(once, []byte("\nMore notes.\n")...)
+	twice := ReplaceManagedBlock(withTail, []byte("@rules/python.md\n"))
+	want = "# Project\n\nHand-written notes.\n\n" + ManagedBegin + "\n@rules/python.md\n" + ManagedEnd + "\n\nMore notes.\n"
+	if string(twice) != want {
+		t.Errorf("unexpected content:\n%s", twice)
+	}
+	block, ok := ExtractManagedBlock(twice)
+	if !ok || string(block) != "@rules/python.md\n" {
+		t.Errorf("unexpected block: %q, %v", block, ok)
+	}
+}
+
+func TestReplaceManagedBlock_EmptyFile(t *testing.T) {
+	got := ReplaceManagedBlock(nil, []byte("x"))
+	if string(got) != ManagedBegin+"\nx\n"+ManagedEnd+"\n" {
+		t.Errorf("unexpected content: %q", got)
+	}
+}
+
+func TestRemoveManagedBlock(t *testing.T) {
+	content := []byte("# Project\n\n" + ManagedBegin + "\nx\n" + ManagedEnd + "\n\nTail.\n")
+	if got := RemoveManagedBlock(content); string(got) != "# Project\n\nTail.\n" {
+		t.Errorf("unexpected content: %q", got)
+	}
+	if got := RemoveManagedBlock([]byte(ManagedBegin + "\nx\n" + ManagedEnd + "\n")); len(got) != 0 {
+		t.Errorf("expected empty content, got %q", got)
+	}
+	if _, ok := ExtractManagedBlock([]byte("no block")); ok {
+		t.Error("expected no block")
+	}
+}
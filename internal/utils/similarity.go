@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Similarity scores how alike two texts are, from 0 to 1, by the words they share regardless of
+// formatting: whitespace, case and markdown punctuation are ignored. Identical wording scores 1.
+func Similarity(a, b string) float64 {
+	wa := wordPattern.FindAllString(strings.ToLower(a), -1)
+	wb := wordPattern.FindAllString(strings.ToLower(b), -1)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	counts := map[string]int{}
+	for _, w := range wa {
+		counts[w]++
+	}
+	common := 0
+	for _, w := range wb {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
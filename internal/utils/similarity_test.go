@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("**Use** tabs.\n\n- Keep   it short", "use tabs keep it short"); got != 1 {
+		t.Errorf("formatting should not matter, got %v", got)
+	}
+	if got := Similarity("one two three four", "one two three five"); got != 0.75 {
+		t.Errorf("expected 0.75, got %v", got)
+	}
+	if got := Similarity("alpha", "beta"); got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+}
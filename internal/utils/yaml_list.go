@@ -0,0 +1,305 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML helpers below edit a single top-level list in place. They touch only the lines that
+// hold the list, so every other key, comment and blank line survives byte for byte, which a
+// decode and re-encode round trip would not guarantee.
+
+// AddYAMLListItem makes value an item of the top-level list key. A missing key is appended as a
+// block list, a scalar value becomes a list holding the old value and the new one, and flow lists
+// ([a, b]) stay flow lists. It reports whether data changed.
+func AddYAMLListItem(data []byte, key, value string) ([]byte, bool, error) {
+	lines := yamlLines(data)
+	idx := findYAMLKey(lines, key)
+	if idx < 0 {
+		out := strings.Join(lines, "")
+		if out != "" && !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		return []byte(out + key + ":\n  - " + yamlScalar(value) + "\n"), true, nil
+	}
+	val, comment := splitYAMLComment(yamlKeyValue(lines[idx]))
+	switch {
+	case val == "":
+		items, first, last, err := yamlBlockItems(lines, idx)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		for _, item := range items {
+			if item == value {
+				return data, false, nil
+			}
+		}
+		indent := "  "
+		if first >= 0 {
+			indent = leadingSpace(lines[first])
+		} else {
+			last = idx
+		}
+		lines = insertLine(lines, last+1, indent+"- "+yamlScalar(value)+"\n")
+	case strings.HasPrefix(val, "["):
+		raw, err := yamlFlowItems(val)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		for _, item := range raw {
+			if unquoteYAML(item) == value {
+				return data, false, nil
+			}
+		}
+		lines[idx] = key + ": [" + strings.Join(append(raw, yamlScalar(value)), ", ") + "]" + comment + lineEnd(lines[idx])
+	default:
+		if unquoteYAML(val) == value {
+			return data, false, nil
+		}
+		lines[idx] = key + ":" + comment + "\n  - " + val + "\n  - " + yamlScalar(value) + lineEnd(lines[idx])
+	}
+	return []byte(strings.Join(lines, "")), true, nil
+}
+
+// RemoveYAMLListItem removes value from the top-level list key, undoing AddYAMLListItem. The key
+// itself is removed once its list is empty. It reports whether data changed.
+func RemoveYAMLListItem(data []byte, key, value string) ([]byte, bool, error) {
+	lines := yamlLines(data)
+	idx := findYAMLKey(lines, key)
+	if idx < 0 {
+		return data, false, nil
+	}
+	val, comment := splitYAMLComment(yamlKeyValue(lines[idx]))
+	switch {
+	case val == "":
+		items, first, last, err := yamlBlockItems(lines, idx)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		if first < 0 {
+			return data, false, nil
+		}
+		var kept []string
+		removed := 0
+		for i := idx + 1; i <= last; i++ {
+			if isYAMLItem(lines[i]) && yamlItemValue(lines[i]) == value {
+				removed++
+				continue
+			}
+			kept = append(kept, lines[i])
+		}
+		if removed == 0 {
+			return data, false, nil
+		}
+		head := lines[:idx+1]
+		if removed == len(items) {
+			head = lines[:idx]
+		}
+		lines = append(append(append([]string{}, head...), kept...), lines[last+1:]...)
+	case strings.HasPrefix(val, "["):
+		raw, err := yamlFlowItems(val)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		var kept []string
+		for _, item := range raw {
+			if unquoteYAML(item) != value {
+				kept = append(kept, item)
+			}
+		}
+		if len(kept) == len(raw) {
+			return data, false, nil
+		}
+		if len(kept) == 0 {
+			lines = append(lines[:idx], lines[idx+1:]...)
+		} else {
+			lines[idx] = key + ": [" + strings.Join(kept, ", ") + "]" + comment + lineEnd(lines[idx])
+		}
+	default:
+		if unquoteYAML(val) != value {
+			return data, false, nil
+		}
+		lines = append(lines[:idx], lines[idx+1:]...)
+	}
+	return []byte(strings.Join(lines, "")), true, nil
+}
+
+// yamlLines splits data into lines that keep their line endings.
+func yamlLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.SplitAfter(string(data), "\n")
+}
+
+// findYAMLKey returns the index of the line defining the top-level key, or -1.
+func findYAMLKey(lines []string, key string) int {
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(key) + `\s*:(\s|$)`)
+	for i, line := range lines {
+		if re.MatchString(line) {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlKeyValue returns what follows the colon on a key line, without the line ending.
+func yamlKeyValue(line string) string {
+	return strings.TrimRight(line[strings.Index(line, ":")+1:], "\r\n")
+}
+
+// yamlBlockItems returns the values of the block list under the key on line idx, together with the
+// indexes of its first and last item lines (-1 when there are none). Blank and comment lines after
+// the last item are left to whatever follows.
+func yamlBlockItems(lines []string, idx int) (items []string, first, last int, err error) {
+	first, last = -1, idx
+	for i := idx + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !isYAMLItem(lines[i]) {
+			if leadingSpace(lines[i]) == "" {
+				break
+			}
+			return nil, 0, 0, fmt.Errorf("line %d: only lists of plain values can be edited", i+1)
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+		items = append(items, yamlItemValue(lines[i]))
+	}
+	return items, first, last, nil
+}
+
+var yamlItemRe = regexp.MustCompile(`^\s*-(\s|$)`)
+
+func isYAMLItem(line string) bool {
+	return yamlItemRe.MatchString(line)
+}
+
+// yamlItemValue returns the unquoted value of a "- value" line.
+func yamlItemValue(line string) string {
+	val, _ := splitYAMLComment(strings.TrimSpace(line)[1:])
+	return unquoteYAML(val)
+}
+
+// yamlFlowItems returns the raw, trimmed items of a one-line flow list such as [a, "b"].
+func yamlFlowItems(val string) ([]string, error) {
+	if !strings.HasSuffix(val, "]") {
+		return nil, fmt.Errorf("flow lists spanning several lines are not supported")
+	}
+	inner := strings.TrimSpace(val[1 : len(val)-1])
+	if inner == "" {
+		return nil, nil
+	}
+	var items []string
+	var quote rune
+	start := 0
+	for i, c := range inner {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, strings.TrimSpace(inner[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, strings.TrimSpace(inner[start:])), nil
+}
+
+// splitYAMLComment separates a value from a trailing comment. The value is trimmed; the comment
+// keeps its leading whitespace so lines can be rebuilt as they were.
+func splitYAMLComment(s string) (value, comment string) {
+	i := yamlCommentStart(s)
+	if i < 0 {
+		return strings.TrimSpace(s), ""
+	}
+	value = strings.TrimRight(s[:i], " \t")
+	return strings.TrimSpace(value), s[len(value):]
+}
+
+// StripYAMLComment removes a trailing # comment and the whitespace before it from a line of YAML.
+// A # inside a quoted value, as in replace: "see issue #42", is text.
+func StripYAMLComment(line string) string {
+	if i := yamlCommentStart(line); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimRight(line, " \t")
+}
+
+// yamlCommentStart returns the index of the # starting a comment in s, or -1. A quote opens a
+// quoted value only where a value can start, so the apostrophe in a plain don't opens none.
+func yamlCommentStart(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" \t:-[,{", s[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteYAML returns the value of a plain, single-quoted or double-quoted scalar.
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+var plainYAMLRe = regexp.MustCompile(`^[A-Za-z0-9_./~][A-Za-z0-9_./~ -]*$`)
+
+// yamlScalar quotes value unless it is safe as a plain scalar.
+func yamlScalar(value string) string {
+	if plainYAMLRe.MatchString(value) && !strings.HasSuffix(value, " ") {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+func lineEnd(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return "\n"
+	}
+	return ""
+}
+
+func insertLine(lines []string, at int, line string) []string {
+	if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += "\n"
+		line = strings.TrimSuffix(line, "\n")
+	}
+	lines = append(lines, "")
+	copy(lines[at+1:], lines[at:])
+	lines[at] = line
+	return lines
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestAddYAMLListItem(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"missing file", "", "read:\n  - CONVENTIONS.md\n"},
+		{"missing key", "# aider settings\nmodel: sonnet", "# aider settings\nmodel: sonnet\nread:\n  - CONVENTIONS.md\n"},
+		{"block list", "read:\n    - docs/A.md # keep\n# trailing\nmodel: sonnet\n", "read:\n    - docs/A.md # keep\n    - CONVENTIONS.md\n# trailing\nmodel: sonnet\n"},
+		{"unindented block list", "read:\n- A.md\nmodel: x\n", "read:\n- A.md\n- CONVENTIONS.md\nmodel: x\n"},
+		{"scalar", "read: A.md # notes\n", "read: # notes\n  - A.md\n  - CONVENTIONS.md\n"},
+		{"flow list", "read: [A.md, 'B.md']  # notes\n", "read: [A.md, 'B.md', CONVENTIONS.md]  # notes\n"},
+		{"empty value", "read:\nmodel: x\n", "read:\n  - CONVENTIONS.md\nmodel: x\n"},
+	}
+	for _, c := range cases {
+		got, changed, err := AddYAMLListItem([]byte(c.in), "read", "CONVENTIONS.md")
+		if err != nil || !changed || string(got) != c.want {
+			t.Errorf("%s: got %q, %v, %v; want %q", c.name, got, changed, err, c.want)
+		}
+	}
+}
+
+func TestAddYAMLListItem_Idempotent(t *testing.T) {
+	for _, in := range []string{"read:\n  - \"CONVENTIONS.md\"\n", "read: CONVENTIONS.md\n", "read: [a, CONVENTIONS.md]\n"} {
+		got, changed, err := AddYAMLListItem([]byte(in), "read", "CONVENTIONS.md")
+		if err != nil || changed || string(got) != in {
+			t.Errorf("%q: expected no change, got %q, %v, %v", in, got, changed, err)
+		}
+	}
+}
+
+func TestAddYAMLListItem_RejectsMapping(t *testing.T) {
+	if _, _, err := AddYAMLListItem([]byte("read:\n  file: A.md\n"), "read", "B.md"); err == nil {
+		t.Error("expected an error for a mapping value")
+	}
+}
+
+func TestRemoveYAMLListItem_UndoesAdd(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"# aider settings\nmodel: sonnet\n",
+		"read:\n  - docs/A.md # keep\n# trailing\nmodel: sonnet\n",
+		"read: [A.md, 'B.md']  # notes\n",
+	} {
+		added, _, err := AddYAMLListItem([]byte(in), "read", "CONVENTIONS.md")
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		got, changed, err := RemoveYAMLListItem(added, "read", "CONVENTIONS.md")
+		if err != nil || !changed || string(got) != in {
+			t.Errorf("round trip of %q: got %q, %v, %v", in, got, changed, err)
+		}
+	}
+	if _, changed, _ := RemoveYAMLListItem([]byte("read: [A.md]\n"), "read", "CONVENTIONS.md"); changed {
+		t.Error("expected no change when the item is absent")
+	}
+}
+
+func TestStripYAMLComment(t *testing.T) {
+	cases := map[string]string{
+		`replace: "see issue #42" # note`:  `replace: "see issue #42"`,
+		`expect: 'a # b'`:                  `expect: 'a # b'`,
+		`text: don't # it's a comment`:     `text: don't`,
+		`text: "say \"#1\"" # x`:           `text: "say \"#1\""`,
+		`- go#concurrency   # one section`: `- go#concurrency`,
+		`# whole line`:                     ``,
+	}
+	for in, want := range cases {
+		if got := StripYAMLComment(in); got != want {
+			t.Errorf("StripYAMLComment(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAddYAMLListItem_ApostropheBeforeComment(t *testing.T) {
+	in := "read: it's.md # notes\n"
+	want := "read: # notes\n  - it's.md\n  - CONVENTIONS.md\n"
+	got, _, err := AddYAMLListItem([]byte(in), "read", "CONVENTIONS.md")
+	if err != nil || string(got) != want {
+		t.Errorf("got %q, %v; want %q", got, err, want)
+	}
+}